@@ -21,11 +21,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 )
@@ -88,9 +92,26 @@ func newRefresher(endpointURL *url.URL, clientID, clientSecret string, client *h
 type authenticationProvider struct {
 	l sync.Mutex
 	// Whether this provider only supports token refresh or not.
-	refreshOnly            bool
+	refreshOnly bool
+	// The grant type used to fetch new tokens.
+	grantType              string
 	clientID, clientSecret string
 	newOAuthTokenCallback  func(token *oauth2.Token)
+	logger                 *slog.Logger
+	tracer                 trace.Tracer
+	observer               Observer
+	// An external source of tokens, which replaces the retrieval of tokens from the API.
+	tokenSource oauth2.TokenSource
+	tokenStore  TokenStore
+	// Whether the stored token has already been loaded.
+	storeLoaded bool
+	// How long before its expiry the token is renewed in the background, if positive.
+	refreshMargin        time.Duration
+	refreshErrorCallback func(err error)
+	refreshTimer         *time.Timer
+	refreshGeneration    int
+	// Whether the background renewal has been stopped by closing the client.
+	refreshClosed bool
 
 	httpClient   *http.Client
 	newToken     tokenProvider
@@ -99,7 +120,10 @@ type authenticationProvider struct {
 }
 
 func newAuthenticationProvider(
-	endpointURL *url.URL, username, password, initialRefreshToken, clientID, clientSecret string, client *http.Client,
+	endpointURL *url.URL,
+	username, password, initialRefreshToken, clientID, clientSecret string,
+	clientCredentialsGrant bool,
+	client *http.Client,
 ) *authenticationProvider {
 	client = wrapTransportWithUserAgent(client, defaultUserAgent)
 	authProvider := authenticationProvider{
@@ -109,10 +133,16 @@ func newAuthenticationProvider(
 		refreshToken: newRefresher(endpointURL, clientID, clientSecret, client),
 	}
 
-	if username != "" {
+	switch {
+	case clientCredentialsGrant:
+		authProvider.refreshOnly = false
+		authProvider.grantType = "client_credentials"
+		authProvider.newToken = clientCredentialsTokenProvider(endpointURL, clientID, clientSecret, client)
+	case username != "":
 		authProvider.refreshOnly = false
+		authProvider.grantType = "password"
 		authProvider.newToken = credentialsTokenProvider(endpointURL, username, password, clientID, clientSecret, client)
-	} else {
+	default:
 		authProvider.refreshOnly = true
 	}
 
@@ -148,11 +178,38 @@ func credentialsTokenProvider(
 	}
 }
 
+// clientCredentialsTokenProvider makes a new token source authenticating as the given OAuth client.
+// New tokens will be fetched with the "client_credentials" grant type.
+func clientCredentialsTokenProvider(
+	endpointURL *url.URL, clientID, clientSecret string, client *http.Client,
+) tokenProvider {
+	cfg := clientcredentials.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		TokenURL:     endpointURL.JoinPath(tokenPath).String(),
+		AuthStyle:    oauth2.AuthStyleInParams,
+	}
+
+	return func(ctx context.Context) (*oauth2.Token, error) {
+		return cfg.TokenSource(context.WithValue(ctx, oauth2.HTTPClient, client)).Token()
+	}
+}
+
 func (ap *authenticationProvider) Token(ctx context.Context) (*oauth2.Token, error) {
+	// The external source caches its token itself, so requests don't wait for each other.
+	if ap.tokenSource != nil {
+		return ap.tokenSource.Token() //nolint:wrapcheck
+	}
+
 	ap.l.Lock()
 	defer ap.l.Unlock()
 
-	var err error
+	ap.loadStoredToken(ctx)
+
+	var (
+		err    error
+		logMsg string
+	)
 
 	switch {
 	case ap.token == nil:
@@ -160,31 +217,94 @@ func (ap *authenticationProvider) Token(ctx context.Context) (*oauth2.Token, err
 			return nil, ErrNoAuthMeanProvided
 		}
 
-		ap.token, err = ap.newToken(ctx)
-	case !ap.token.Valid():
-		if ap.token.RefreshToken == "" {
+		logMsg = "Fetched a new OAuth token"
+		ap.token, err = ap.tracedNewToken(ctx)
+	case !ap.token.Valid() && ap.token.RefreshToken == "":
+		// Tokens obtained with the client_credentials grant can't be refreshed
+		if ap.newToken == nil {
 			return nil, errTokenHasNoRefresh
 		}
 
-		ap.token, err = ap.refreshToken(ctx, ap.token.RefreshToken)
+		logMsg = "Fetched a new OAuth token"
+		ap.token, err = ap.tracedNewToken(ctx)
+	case !ap.token.Valid():
+		logMsg = "Refreshed the OAuth token"
+		ap.token, err = ap.tracedRefreshToken(ctx, ap.token.RefreshToken)
 		if err != nil {
 			if !ap.refreshOnly {
-				ap.token, err = ap.newToken(ctx)
+				orDiscard(ap.logger).LogAttrs(ctx, slog.LevelWarn, "Failed to refresh the OAuth token, fetching a new one",
+					slog.Any("error", err))
+
+				logMsg = "Fetched a new OAuth token"
+				ap.token, err = ap.tracedNewToken(ctx)
 			}
 		}
 	default:
 		return ap.token, nil
 	}
 
-	// A new token has been retrieved (if no error occurred)
-	if ap.newOAuthTokenCallback != nil && err == nil {
-		ap.newOAuthTokenCallback(ap.token)
+	if err != nil {
+		orDiscard(ap.logger).LogAttrs(ctx, slog.LevelWarn, "Failed to retrieve an OAuth token", slog.Any("error", err))
+
+		return ap.token, err
+	}
+
+	ap.tokenRetrieved(ctx, logMsg)
+
+	return ap.token, nil
+}
+
+// loadStoredToken replaces the current token with the one from the token store, if any,
+// the first time a token is needed.
+func (ap *authenticationProvider) loadStoredToken(ctx context.Context) {
+	if ap.tokenStore == nil || ap.storeLoaded {
+		return
+	}
+
+	ap.storeLoaded = true
+
+	tk, err := ap.tokenStore.Load(ctx)
+	if err != nil {
+		orDiscard(ap.logger).LogAttrs(ctx, slog.LevelWarn, "Failed to load the stored OAuth token", slog.Any("error", err))
+
+		return
+	}
+
+	// A token which can't be used nor refreshed is useless
+	if tk == nil || (!tk.Valid() && tk.RefreshToken == "") {
+		return
 	}
 
-	return ap.token, err
+	ap.token = tk
+
+	orDiscard(ap.logger).LogAttrs(ctx, slog.LevelDebug, "Loaded the stored OAuth token", slog.Time("expiry", tk.Expiry))
+
+	ap.scheduleRefresh()
+}
+
+// tokenRetrieved must be called when a new token has been retrieved,
+// to log it, store it, schedule its renewal and notify the callback.
+func (ap *authenticationProvider) tokenRetrieved(ctx context.Context, logMsg string) {
+	orDiscard(ap.logger).LogAttrs(ctx, slog.LevelInfo, logMsg, slog.Time("expiry", ap.token.Expiry))
+
+	ap.scheduleRefresh()
+
+	if ap.tokenStore != nil {
+		if err := ap.tokenStore.Save(ctx, ap.token); err != nil {
+			orDiscard(ap.logger).LogAttrs(ctx, slog.LevelWarn, "Failed to store the OAuth token", slog.Any("error", err))
+		}
+	}
+
+	if ap.newOAuthTokenCallback != nil {
+		ap.newOAuthTokenCallback(ap.token)
+	}
 }
 
 func (ap *authenticationProvider) refetchToken(ctx context.Context) error {
+	if ap.tokenSource != nil {
+		return ErrExternalTokenRejected
+	}
+
 	if ap.refreshOnly {
 		return ErrTokenIsRefreshOnly
 	}
@@ -192,7 +312,7 @@ func (ap *authenticationProvider) refetchToken(ctx context.Context) error {
 	ap.l.Lock()
 	defer ap.l.Unlock()
 
-	tk, err := ap.newToken(ctx)
+	tk, err := ap.tracedNewToken(ctx)
 	if err != nil {
 		if retErr := new(oauth2.RetrieveError); errors.As(err, &retErr) {
 			return buildAuthError(tokenPath, retErr)
@@ -203,13 +323,37 @@ func (ap *authenticationProvider) refetchToken(ctx context.Context) error {
 
 	ap.token = tk
 
-	if ap.newOAuthTokenCallback != nil {
-		ap.newOAuthTokenCallback(ap.token)
-	}
+	ap.tokenRetrieved(ctx, "Fetched a new OAuth token")
 
 	return nil
 }
 
+// tracedNewToken fetches a new token within a dedicated span, and reports it to the observer.
+func (ap *authenticationProvider) tracedNewToken(ctx context.Context) (*oauth2.Token, error) {
+	ctx, span := startSpan(ctx, ap.tracer, "OAuth token fetch", trace.SpanKindClient,
+		attribute.String(attrGrantType, ap.grantType))
+
+	tk, err := ap.newToken(ctx)
+
+	endSpan(span, 0, err)
+	orNoop(ap.observer).TokenRetrieved(false, err)
+
+	return tk, err
+}
+
+// tracedRefreshToken refreshes the token within a dedicated span, and reports it to the observer.
+func (ap *authenticationProvider) tracedRefreshToken(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+	ctx, span := startSpan(ctx, ap.tracer, "OAuth token refresh", trace.SpanKindClient,
+		attribute.String(attrGrantType, "refresh_token"))
+
+	tk, err := ap.refreshToken(ctx, refreshToken)
+
+	endSpan(span, 0, err)
+	orNoop(ap.observer).TokenRetrieved(true, err)
+
+	return tk, err
+}
+
 func (ap *authenticationProvider) injectHeader(ctx context.Context, req *http.Request) error {
 	tk, err := ap.Token(ctx)
 	if err != nil {
@@ -229,8 +373,39 @@ func (ap *authenticationProvider) logout(ctx context.Context, endpoint string) e
 	ap.l.Lock()
 	defer ap.l.Unlock()
 
-	if ap.token == nil || !ap.token.Valid() {
-		return nil // No need to perform a logout
+	if ap.tokenSource != nil {
+		ap.stopRefresh()
+
+		return nil // External tokens are managed by their owner
+	}
+
+	// The stored token must be revoked, even if no request has been sent yet.
+	ap.loadStoredToken(ctx)
+
+	// Logging out stops the background renewal, even if the token has already expired.
+	ap.stopRefresh()
+
+	err := ap.revokeToken(ctx, endpoint)
+	if err == nil {
+		ap.token = nil
+	}
+
+	// The stored token is deleted even if it couldn't be revoked, so that it isn't used anymore.
+	if ap.tokenStore != nil {
+		if deleteErr := ap.tokenStore.Delete(ctx); deleteErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to delete the stored token: %w", deleteErr))
+		}
+	}
+
+	return err
+}
+
+// revokeToken revokes the current token, through its refresh token if it has one,
+// which also revokes the related access token, or through its access token if it's still valid.
+// The authentication provider must be locked.
+func (ap *authenticationProvider) revokeToken(ctx context.Context, endpoint string) error {
+	if ap.token == nil || (ap.token.RefreshToken == "" && !ap.token.Valid()) {
+		return nil // Nothing to revoke
 	}
 
 	endpointURL, err := url.Parse(endpoint)
@@ -249,11 +424,16 @@ func (ap *authenticationProvider) logout(ctx context.Context, endpoint string) e
 		"token":           {ap.token.RefreshToken},
 	}
 
+	if ap.token.RefreshToken == "" {
+		// Tokens obtained with the client_credentials grant have no refresh token
+		values.Set("token_type_hint", "access_token")
+		values.Set("token", ap.token.AccessToken)
+	}
+
 	if ap.clientSecret != "" {
 		values.Set("client_secret", ap.clientSecret)
 	}
 
-	// Revoking the refresh token will also revoke the related access token
 	body := strings.NewReader(values.Encode())
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL.String(), body)
@@ -275,7 +455,7 @@ func (ap *authenticationProvider) logout(ctx context.Context, endpoint string) e
 		return fmt.Errorf("%w: server replyed with status code %d", ErrTokenRevoke, resp.StatusCode)
 	}
 
-	ap.token = nil
+	orDiscard(ap.logger).LogAttrs(ctx, slog.LevelInfo, "Revoked the OAuth token")
 
 	return nil
 }
@@ -294,7 +474,7 @@ func buildAuthError(reqPath string, retErr *oauth2.RetrieveError) *AuthError {
 	}
 }
 
-func buildAuthErrorFromBody(apiErr *APIError) error {
+func buildAuthErrorFromBody(apiErr *APIError) *AuthError {
 	authErr := AuthError{
 		APIError: apiErr,
 	}
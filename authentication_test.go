@@ -451,3 +451,192 @@ func TestAuthentication(t *testing.T) { //nolint:maintidx
 		})
 	})
 }
+
+func TestClientCredentialsGrant(t *testing.T) {
+	t.Parallel()
+
+	const clientID, clientSecret = "app", "app-secret"
+
+	tokenCount := 0
+	authHandler := func(r *http.Request) (statusCode int, body []byte, err error) {
+		if err = r.ParseForm(); err != nil {
+			t.Fatal("Failed to parse token request:", err)
+		}
+
+		expectedForm := url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {clientID},
+			"client_secret": {clientSecret},
+		}
+		if diff := cmp.Diff(expectedForm, r.PostForm); diff != "" {
+			t.Fatalf("Unexpected token request (-want +got):\n%s", diff)
+		}
+
+		tokenCount++
+
+		return http.StatusOK, []byte(fmt.Sprintf(
+			`{"access_token": "access-%d", "expires_in": 3600, "token_type": "Bearer"}`, tokenCount,
+		)), nil
+	}
+
+	var revokeForm url.Values
+
+	revokeHandler := func(r *http.Request) (statusCode int, body []byte, err error) {
+		if err = r.ParseForm(); err != nil {
+			t.Fatal("Failed to parse revoke request:", err)
+		}
+
+		revokeForm = r.PostForm
+
+		return http.StatusOK, []byte(`{}`), nil
+	}
+
+	expectedAccessTk := "access-1"
+
+	client, requestCounter, err := makeClientMockForAuth(
+		t, authHandler, revokeHandler, &expectedAccessTk, WithOAuthClient(clientID, clientSecret), WithClientCredentialsGrant(),
+	)
+	if err != nil {
+		t.Fatal("Failed to init client:", err)
+	}
+
+	if _, err = client.Get(context.Background(), ResourceAgent, "<id>"); err != nil {
+		t.Fatal("Failed to execute request:", err)
+	}
+
+	// Since the token has no refresh token, a new one is fetched once it has expired.
+	client.authProvider.token.Expiry = time.Now().Add(-time.Minute)
+	expectedAccessTk = "access-2"
+
+	if _, err = client.Get(context.Background(), ResourceAgent, "<id>"); err != nil {
+		t.Fatal("Failed to execute request:", err)
+	}
+
+	// When the token is rejected by the API, a new one is fetched too.
+	expectedAccessTk = "access-3"
+
+	if _, err = client.Get(context.Background(), ResourceAgent, "<id>"); err != nil {
+		t.Fatal("Failed to execute request:", err)
+	}
+
+	if requestCounter[tokenPath] != 3 {
+		t.Fatalf("Expected 3 token requests, got %d", requestCounter[tokenPath])
+	}
+
+	if err = client.Logout(context.Background()); err != nil {
+		t.Fatal("Failed to logout:", err)
+	}
+
+	expectedRevokeForm := url.Values{
+		"client_id":       {clientID},
+		"client_secret":   {clientSecret},
+		"token_type_hint": {"access_token"},
+		"token":           {"access-3"},
+	}
+	if diff := cmp.Diff(expectedRevokeForm, revokeForm); diff != "" {
+		t.Fatalf("Unexpected revoke request (-want +got):\n%s", diff)
+	}
+}
+
+type tokenSourceFunc func() (*oauth2.Token, error)
+
+func (f tokenSourceFunc) Token() (*oauth2.Token, error) {
+	return f()
+}
+
+func TestExternalToken(t *testing.T) {
+	t.Parallel()
+
+	t.Run("static token", func(t *testing.T) {
+		t.Parallel()
+
+		expectedAccessTk := "static"
+
+		client, requestCounter, err := makeClientMockForAuth(
+			t, authMockHandler, okHandler, &expectedAccessTk, WithStaticToken("static"), WithCredentials("u", "p"),
+		)
+		if err != nil {
+			t.Fatal("Failed to init client:", err)
+		}
+
+		if _, err = client.Get(context.Background(), ResourceAgent, "<id>"); err != nil {
+			t.Fatal("Failed to execute request:", err)
+		}
+
+		// The API now rejects the token
+		expectedAccessTk = "other"
+
+		_, err = client.Get(context.Background(), ResourceAgent, "<id>")
+		if authErr := new(AuthError); !errors.As(err, &authErr) || authErr.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("Expected a 401 AuthError, got %v", err)
+		}
+
+		if !errors.Is(err, ErrExternalTokenRejected) {
+			t.Fatalf("Expected error %v, got %v", ErrExternalTokenRejected, err)
+		}
+
+		if err = client.Logout(context.Background()); err != nil {
+			t.Fatal("Failed to logout:", err)
+		}
+
+		// The token is neither refetched nor revoked, and the rejected request isn't retried.
+		expectedRequests := map[string]int{"/v1/agent/<id>/": 2}
+		if diff := cmp.Diff(expectedRequests, requestCounter); diff != "" {
+			t.Fatalf("Unexpected requests (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("token source", func(t *testing.T) {
+		t.Parallel()
+
+		// The first token has already expired, so the second one is requested by the next request.
+		tokens := []*oauth2.Token{
+			{AccessToken: "source-1", Expiry: time.Now()},
+			{AccessToken: "source-2", Expiry: time.Now().Add(time.Hour)},
+		}
+		calls := 0
+		expectedAccessTk := "source-1"
+		source := tokenSourceFunc(func() (*oauth2.Token, error) {
+			tk := tokens[calls]
+			calls++
+
+			return tk, nil
+		})
+
+		client, _, err := makeClientMockForAuth(t, authMockHandler, okHandler, &expectedAccessTk, WithTokenSource(source))
+		if err != nil {
+			t.Fatal("Failed to init client:", err)
+		}
+
+		for _, accessToken := range []string{"source-1", "source-2", "source-2"} {
+			expectedAccessTk = accessToken
+
+			if _, err = client.Get(context.Background(), ResourceAgent, "<id>"); err != nil {
+				t.Fatal("Failed to execute request:", err)
+			}
+		}
+
+		if calls != 2 {
+			t.Fatalf("Expected the source to be called 2 times, got %d", calls)
+		}
+	})
+
+	t.Run("token source error", func(t *testing.T) {
+		t.Parallel()
+
+		errSource := errors.New("secret manager unavailable") //nolint:err113
+		source := tokenSourceFunc(func() (*oauth2.Token, error) {
+			return nil, errSource
+		})
+		expectedAccessTk := ""
+
+		client, _, err := makeClientMockForAuth(t, authMockHandler, okHandler, &expectedAccessTk, WithTokenSource(source))
+		if err != nil {
+			t.Fatal("Failed to init client:", err)
+		}
+
+		if _, err = client.Get(context.Background(), ResourceAgent, "<id>"); !errors.Is(err, errSource) {
+			t.Fatalf("Expected error %v, got %v", errSource, err)
+		}
+	})
+}
@@ -0,0 +1,229 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CacheEntry is a response body stored in a [ResponseCache], along with its validators.
+type CacheEntry struct {
+	// The resource the entry belongs to, used for invalidation.
+	Resource Resource
+	// The validators returned by the API in the ETag and Last-Modified response headers.
+	ETag, LastModified string
+	Body               json.RawMessage
+}
+
+// A ResponseCache stores the responses of GET requests,
+// so that conditional requests can be sent instead of downloading unchanged resources again.
+// Keys are made of a hash of the identity the client authenticates as, the Bleemeo account header
+// and the request URL, so that a cache shared by clients authenticating as different users
+// never returns the response of a user to another one.
+// When the client doesn't know who it authenticates as, e.g. with WithTokenSource or an initial refresh token,
+// the access token is hashed instead, so the cached entries aren't reused after the token has been renewed.
+// Implementations must be safe for concurrent use.
+type ResponseCache interface {
+	// Get returns the entry stored with the given key, if any.
+	Get(key string) (CacheEntry, bool)
+	// Set stores the given entry with the given key.
+	Set(key string, entry CacheEntry)
+	// Invalidate removes all the entries of the given resource.
+	Invalidate(resource Resource)
+}
+
+// NewLRUCache returns an in-memory [ResponseCache] holding up to maxEntries entries,
+// evicting the least recently used ones first.
+func NewLRUCache(maxEntries int) ResponseCache {
+	return &lruCache{
+		maxEntries: max(maxEntries, 1),
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+type lruCacheItem struct {
+	key   string
+	entry CacheEntry
+}
+
+type lruCache struct {
+	l          sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	// Most recently used items are at the front of the list.
+	order *list.List
+}
+
+func (lru *lruCache) Get(key string) (CacheEntry, bool) {
+	lru.l.Lock()
+	defer lru.l.Unlock()
+
+	elem, ok := lru.entries[key]
+	if !ok {
+		return CacheEntry{}, false
+	}
+
+	lru.order.MoveToFront(elem)
+
+	return elem.Value.(*lruCacheItem).entry, true //nolint:forcetypeassert
+}
+
+func (lru *lruCache) Set(key string, entry CacheEntry) {
+	lru.l.Lock()
+	defer lru.l.Unlock()
+
+	if elem, ok := lru.entries[key]; ok {
+		elem.Value.(*lruCacheItem).entry = entry //nolint:forcetypeassert
+		lru.order.MoveToFront(elem)
+
+		return
+	}
+
+	lru.entries[key] = lru.order.PushFront(&lruCacheItem{key: key, entry: entry})
+
+	for lru.order.Len() > lru.maxEntries {
+		lru.remove(lru.order.Back())
+	}
+}
+
+func (lru *lruCache) Invalidate(resource Resource) {
+	lru.l.Lock()
+	defer lru.l.Unlock()
+
+	for elem := lru.order.Front(); elem != nil; {
+		next := elem.Next()
+
+		if elem.Value.(*lruCacheItem).entry.Resource == resource { //nolint:forcetypeassert
+			lru.remove(elem)
+		}
+
+		elem = next
+	}
+}
+
+func (lru *lruCache) remove(elem *list.Element) {
+	lru.order.Remove(elem)
+	delete(lru.entries, elem.Value.(*lruCacheItem).key) //nolint:forcetypeassert
+}
+
+// cacheKey returns the key of the given request in a ResponseCache.
+// The key is scoped to the given identity, or to the Authorization header of the request if it is empty.
+func cacheKey(req *http.Request, identity string) string {
+	if identity == "" {
+		identity = req.Header.Get("Authorization")
+	}
+
+	sum := sha256.Sum256([]byte(identity))
+
+	return hex.EncodeToString(sum[:16]) + " " + req.Header.Get("X-Bleemeo-Account") + " " + req.URL.String()
+}
+
+// cacheMiddleware makes GET requests conditional when a response to them is stored in the given cache,
+// and returns the stored body when the API replies with a 304 status code.
+// Requests modifying a resource invalidate all the cached entries of this resource.
+// The entries are scoped to the given identity, see cacheKey.
+func cacheMiddleware(cache ResponseCache, identity string) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resource := resourceFromPath(req.URL.Path)
+
+			if req.Method != http.MethodGet {
+				resp, err := next(req)
+
+				if req.Method != http.MethodHead && req.Method != http.MethodOptions {
+					cache.Invalidate(resource)
+				}
+
+				return resp, err
+			}
+
+			// Don't interfere with conditional requests made by the caller.
+			if req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+				return next(req)
+			}
+
+			key := cacheKey(req, identity)
+
+			entry, cached := cache.Get(key)
+			if cached {
+				req = req.Clone(req.Context())
+
+				if entry.ETag != "" {
+					req.Header.Set("If-None-Match", entry.ETag)
+				}
+
+				if entry.LastModified != "" {
+					req.Header.Set("If-Modified-Since", entry.LastModified)
+				}
+			}
+
+			resp, err := next(req)
+			if err != nil {
+				return nil, err
+			}
+
+			switch {
+			case resp.StatusCode == http.StatusNotModified && cached:
+				cleanupResponse(resp)
+
+				resp.StatusCode = http.StatusOK
+				resp.Status = "200 " + http.StatusText(http.StatusOK)
+				resp.Header.Set("Content-Type", "application/json")
+				resp.ContentLength = int64(len(entry.Body))
+				resp.Header.Set("Content-Length", strconv.Itoa(len(entry.Body)))
+				resp.Body = io.NopCloser(bytes.NewReader(entry.Body))
+			case resp.StatusCode == http.StatusOK && isCacheable(resp):
+				body, err := io.ReadAll(resp.Body)
+				_ = resp.Body.Close()
+
+				if err != nil {
+					return nil, err //nolint:wrapcheck
+				}
+
+				cache.Set(key, CacheEntry{
+					Resource:     resource,
+					ETag:         resp.Header.Get("ETag"),
+					LastModified: resp.Header.Get("Last-Modified"),
+					Body:         body,
+				})
+
+				resp.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			return resp, nil
+		}
+	}
+}
+
+// isCacheable returns whether the given response has validators and may be stored.
+func isCacheable(resp *http.Response) bool {
+	if resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "" {
+		return false
+	}
+
+	return !strings.Contains(resp.Header.Get("Cache-Control"), "no-store")
+}
@@ -0,0 +1,178 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLRUCache(t *testing.T) {
+	t.Parallel()
+
+	cache := NewLRUCache(2)
+
+	cache.Set("a", CacheEntry{Resource: ResourceAgent, ETag: "1"})
+	cache.Set("b", CacheEntry{Resource: ResourceService, ETag: "2"})
+
+	if _, ok := cache.Get("a"); !ok { // "b" is now the least recently used entry
+		t.Fatal("Expected entry a to be cached")
+	}
+
+	cache.Set("c", CacheEntry{Resource: ResourceAgent, ETag: "3"})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Fatal("Expected entry b to be evicted")
+	}
+
+	if entry, ok := cache.Get("c"); !ok || entry.ETag != "3" {
+		t.Fatalf("Unexpected entry c: %v (found: %t)", entry, ok)
+	}
+
+	cache.Invalidate(ResourceAgent)
+
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); ok {
+			t.Fatalf("Expected entry %s to be invalidated", key)
+		}
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	t.Parallel()
+
+	const etag = `"v1"`
+
+	var conditionalHeaders []string
+
+	agentVersion := 1
+	requestCounter := make(map[string]int)
+	clientMock := &http.Client{
+		Transport: &transportMock{
+			handlers: map[string]mockHandler{
+				tokenPath: authMockHandler,
+				"/v1/agent/1/": func(r *http.Request) (int, []byte, error) {
+					conditionalHeaders = append(conditionalHeaders, r.Header.Get("If-None-Match"))
+
+					if r.Header.Get("If-None-Match") == etag {
+						return http.StatusNotModified, nil, nil
+					}
+
+					return http.StatusOK, fmt.Appendf(nil, `{"id": "1", "version": %d}`, agentVersion), nil
+				},
+			},
+			counters: requestCounter,
+			headers: map[string]http.Header{
+				"/v1/agent/1/": {"Etag": {etag}},
+			},
+		},
+	}
+
+	client, err := NewClient(WithCredentials("u", ""), WithHTTPClient(clientMock), WithResponseCache(NewLRUCache(10)))
+	if err != nil {
+		t.Fatal("Failed to initialize client:", err)
+	}
+
+	ctx := context.Background()
+
+	for range 2 {
+		agent, err := client.Get(ctx, ResourceAgent, "1")
+		if err != nil {
+			t.Fatal("Failed to get agent:", err)
+		}
+
+		if string(agent) != `{"id": "1", "version": 1}` {
+			t.Fatalf("Unexpected agent: %s", agent)
+		}
+	}
+
+	agentVersion = 2
+
+	_, err = client.Update(ctx, ResourceAgent, "1", map[string]string{"display_name": "agent"})
+	if err != nil {
+		t.Fatal("Failed to update agent:", err)
+	}
+
+	agent, err := client.Get(ctx, ResourceAgent, "1")
+	if err != nil {
+		t.Fatal("Failed to get agent:", err)
+	}
+
+	if string(agent) != `{"id": "1", "version": 2}` {
+		t.Fatalf("Unexpected agent after update: %s", agent)
+	}
+
+	// Only the second request should have been conditional,
+	// the update having invalidated the cached agent.
+	expectedHeaders := []string{"", etag, "", ""}
+	if diff := cmp.Diff(expectedHeaders, conditionalHeaders); diff != "" {
+		t.Fatalf("Unexpected If-None-Match headers (-want +got):\n%s", diff)
+	}
+}
+
+func TestResponseCacheSharedByUsers(t *testing.T) {
+	t.Parallel()
+
+	const etag = `"v1"`
+
+	var conditionalHeaders []string
+
+	clientMock := &http.Client{
+		Transport: &transportMock{
+			handlers: map[string]mockHandler{
+				tokenPath: authMockHandler,
+				"/v1/agent/1/": func(r *http.Request) (int, []byte, error) {
+					conditionalHeaders = append(conditionalHeaders, r.Header.Get("If-None-Match"))
+
+					if r.Header.Get("If-None-Match") == etag {
+						return http.StatusNotModified, nil, nil
+					}
+
+					return http.StatusOK, []byte(`{"id": "1"}`), nil
+				},
+			},
+			counters: make(map[string]int),
+			headers: map[string]http.Header{
+				"/v1/agent/1/": {"Etag": {etag}},
+			},
+		},
+	}
+
+	cache := NewLRUCache(10)
+
+	for _, username := range []string{"u1", "u2", "u1"} {
+		client, err := NewClient(WithCredentials(username, ""), WithHTTPClient(clientMock), WithResponseCache(cache))
+		if err != nil {
+			t.Fatal("Failed to initialize client:", err)
+		}
+
+		_, err = client.Get(context.Background(), ResourceAgent, "1")
+		if err != nil {
+			t.Fatal("Failed to get agent:", err)
+		}
+	}
+
+	// The response cached for the first user mustn't be used for the second one.
+	expectedHeaders := []string{"", "", etag}
+	if diff := cmp.Diff(expectedHeaders, conditionalHeaders); diff != "" {
+		t.Fatalf("Unexpected If-None-Match headers (-want +got):\n%s", diff)
+	}
+}
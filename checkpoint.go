@@ -0,0 +1,82 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// A Checkpoint is the position of an [Iterator] in a listing,
+// which can be serialized to JSON to resume the iteration later, possibly from another process.
+type Checkpoint struct {
+	// The path of the page being read, including its query parameters.
+	PageURL string `json:"page_url"`
+	// The number of resources of the page which have already been returned.
+	Index int `json:"index"`
+}
+
+// WithCheckpoint makes the iterator resume from the given checkpoint,
+// as returned by Iterator.Checkpoint(), instead of starting from the first page.
+// The parameters given to Client.Iterator() are ignored, those of the page URL being used instead.
+// Checkpoints aren't supported when fetching pages in parallel.
+// If the page URL is absolute, its scheme and host must be those of the client endpoint,
+// otherwise the iteration fails with ErrCheckpointForeignURL, so that the token of the client
+// is never sent to another host.
+func WithCheckpoint(checkpoint Checkpoint) IteratorOption {
+	return func(iter *iterator) {
+		pageURL, err := url.Parse(checkpoint.PageURL)
+		if err != nil {
+			iter.err = fmt.Errorf("invalid checkpoint page URL: %w", err)
+
+			return
+		}
+
+		if pageURL.Scheme != "" || pageURL.Host != "" {
+			if pageURL.Scheme != iter.c.epURL.Scheme || pageURL.Host != iter.c.epURL.Host {
+				iter.err = fmt.Errorf("%w: %s", ErrCheckpointForeignURL, checkpoint.PageURL)
+
+				return
+			}
+		}
+
+		iter.pageURL = pageURL.RequestURI()
+		iter.skip = checkpoint.Index
+	}
+}
+
+func (iter *iterator) Checkpoint() Checkpoint {
+	if iter.concurrency > 0 {
+		return Checkpoint{}
+	}
+
+	return Checkpoint{
+		PageURL: pagePath(iter.pageURL),
+		Index:   iter.index + iter.skip,
+	}
+}
+
+// pagePath returns the path and query of the given page URL,
+// so that checkpoints don't depend on the endpoint of the client.
+func pagePath(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return pageURL
+	}
+
+	return u.RequestURI()
+}
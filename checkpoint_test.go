@@ -0,0 +1,167 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	const totalResources = 15 // the page size is set to 5
+
+	t.Run("resume after stop", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := makeClientMockForIteration(t, makeMetricMockHandler(t, totalResources))
+		iter := client.Iterator(ResourceMetric, url.Values{"page_size": {"5"}})
+
+		expectedCheckpoint := Checkpoint{PageURL: "/v1/metric/?page_size=5", Index: 0}
+		if diff := cmp.Diff(expectedCheckpoint, iter.Checkpoint()); diff != "" {
+			t.Fatalf("Unexpected initial checkpoint (-want +got):\n%s", diff)
+		}
+
+		for range 7 {
+			if !iter.Next(context.Background()) {
+				t.Fatal("Unexpected end of iteration:", iter.Err())
+			}
+		}
+
+		iter.Close()
+
+		data, err := json.Marshal(iter.Checkpoint())
+		if err != nil {
+			t.Fatal("Failed to marshal checkpoint:", err)
+		}
+
+		var checkpoint Checkpoint
+
+		if err = json.Unmarshal(data, &checkpoint); err != nil {
+			t.Fatal("Failed to unmarshal checkpoint:", err)
+		}
+
+		expectedCheckpoint = Checkpoint{PageURL: "/v1/metric/?page=2&page_size=5", Index: 2}
+		if diff := cmp.Diff(expectedCheckpoint, checkpoint); diff != "" {
+			t.Fatalf("Unexpected checkpoint (-want +got):\n%s", diff)
+		}
+
+		resumedClient, requestCounter := makeClientMockForIteration(t, makeMetricMockHandler(t, totalResources))
+		resumedIter := resumedClient.Iterator(ResourceMetric, nil, WithCheckpoint(checkpoint))
+
+		ids := collectIDs(t, resumedIter)
+		if err = resumedIter.Err(); err != nil {
+			t.Fatal("Iterator error:", err)
+		}
+
+		if diff := cmp.Diff([]int{8, 9, 10, 11, 12, 13, 14, 15}, ids); diff != "" {
+			t.Fatalf("Unexpected IDs (-want +got):\n%s", diff)
+		}
+
+		if requestCounter["/v1/metric/"] != 2 {
+			t.Fatalf("Expected 2 pages to be fetched, got %d", requestCounter["/v1/metric/"])
+		}
+	})
+
+	t.Run("resume after error", func(t *testing.T) {
+		t.Parallel()
+
+		failed := false
+		metricHandler := makeMetricMockHandler(t, totalResources)
+		handler := func(r *http.Request) (int, []byte, error) {
+			if r.URL.Query().Get("page") == "2" && !failed {
+				failed = true
+
+				return http.StatusServiceUnavailable, nil, nil
+			}
+
+			return metricHandler(r)
+		}
+
+		client, _ := makeClientMockForIteration(t, handler)
+		iter := client.Iterator(ResourceMetric, url.Values{"page_size": {"5"}})
+
+		ids := collectIDs(t, iter)
+		if iter.Err() == nil {
+			t.Fatal("Expected an iteration error")
+		}
+
+		expectedCheckpoint := Checkpoint{PageURL: "/v1/metric/?page=2&page_size=5", Index: 0}
+		if diff := cmp.Diff(expectedCheckpoint, iter.Checkpoint()); diff != "" {
+			t.Fatalf("Unexpected checkpoint (-want +got):\n%s", diff)
+		}
+
+		resumedIter := client.Iterator(ResourceMetric, nil, WithCheckpoint(iter.Checkpoint()))
+
+		ids = append(ids, collectIDs(t, resumedIter)...)
+		if err := resumedIter.Err(); err != nil {
+			t.Fatal("Iterator error:", err)
+		}
+
+		expectedIDs := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+		if diff := cmp.Diff(expectedIDs, ids); diff != "" {
+			t.Fatalf("Unexpected IDs (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("foreign page URL", func(t *testing.T) {
+		t.Parallel()
+
+		client, requestCounter := makeClientMockForIteration(t, makeMetricMockHandler(t, totalResources))
+
+		for _, pageURL := range []string{
+			"https://attacker.example.com/v1/metric/?page=2",
+			"http" + strings.TrimPrefix(defaultEndpoint, "https") + "/v1/metric/?page=2",
+			"//attacker.example.com/v1/metric/?page=2",
+		} {
+			iter := client.Iterator(ResourceMetric, nil, WithCheckpoint(Checkpoint{PageURL: pageURL}))
+
+			if iter.Next(context.Background()) {
+				t.Fatalf("Expected the iteration to fail for %s", pageURL)
+			}
+
+			if !errors.Is(iter.Err(), ErrCheckpointForeignURL) {
+				t.Fatalf("Expected error %v for %s, got %v", ErrCheckpointForeignURL, pageURL, iter.Err())
+			}
+		}
+
+		if requestCounter["/v1/metric/"] != 0 {
+			t.Fatalf("Expected no page to be fetched, got %d", requestCounter["/v1/metric/"])
+		}
+
+		iter := client.Iterator(ResourceMetric, nil, WithCheckpoint(Checkpoint{
+			PageURL: defaultEndpoint + "/v1/metric/?page=3&page_size=5",
+			Index:   3,
+		}))
+
+		if diff := cmp.Diff([]int{14, 15}, collectIDs(t, iter)); diff != "" {
+			t.Fatalf("Unexpected IDs (-want +got):\n%s", diff)
+		}
+
+		if err := iter.Err(); err != nil {
+			t.Fatal("Iterator error:", err)
+		}
+	})
+}
@@ -23,12 +23,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strconv"
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
 )
 
@@ -48,16 +52,31 @@ type Client struct {
 	oAuthClientID             string
 	oAuthClientSecret         string
 	oAuthInitialRefresh       string
+	clientCredentialsGrant    bool
 	client                    *http.Client
 	newOAuthTokenCallback     func(token *oauth2.Token)
+	tokenSource               oauth2.TokenSource
+	tokenStore                TokenStore
+	tokenRefreshMargin        time.Duration
+	tokenRefreshErrorCallback func(err error)
 	headers                   map[string]string
 	throttleMaxAutoRetryDelay time.Duration
+	retryPolicy               *RetryPolicy
+	rateLimiter               RateLimiter
+	middlewares               []Middleware
+	logger                    *slog.Logger
+	tracer                    trace.Tracer
+	observer                  Observer
+	responseCache             ResponseCache
+	requestGroup              *requestGroup
+	queueThrottled            bool
 
 	epURL        *url.URL
 	authProvider *authenticationProvider
 
 	l                sync.Mutex
 	throttleDeadline time.Time
+	throttleQueue    throttleQueue
 }
 
 // NewClient initializes a Bleemeo API client with the given options.
@@ -69,22 +88,16 @@ type Client struct {
 // The Client will take care of obtaining and refreshing the OAuth token
 // to authenticate against the Bleemeo API.
 func NewClient(opts ...ClientOption) (*Client, error) {
-	c := &Client{
-		endpoint:                  defaultEndpoint,
-		oAuthClientID:             defaultOAuthClientID,
-		client:                    new(http.Client),
-		headers:                   map[string]string{"User-Agent": defaultUserAgent},
-		throttleMaxAutoRetryDelay: defaultThrottleMaxAutoRetryDelay,
-	}
+	c := configureClient(opts)
 
-	for _, opt := range opts {
-		if opt != nil {
-			opt(c)
-		}
+	hasAuthMean := c.username != "" || c.oAuthInitialRefresh != "" || c.clientCredentialsGrant ||
+		c.tokenStore != nil || c.tokenSource != nil
+	if !hasAuthMean {
+		return nil, ErrNoAuthMeanProvided
 	}
 
-	if c.username == "" && c.oAuthInitialRefresh == "" {
-		return nil, ErrNoAuthMeanProvided
+	if c.clientCredentialsGrant && c.oAuthClientSecret == "" {
+		return nil, ErrNoOAuthClientSecret
 	}
 
 	epURL, err := url.Parse(c.endpoint)
@@ -94,16 +107,65 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 
 	c.epURL = epURL
 	c.authProvider = newAuthenticationProvider(
-		c.epURL, c.username, c.password, c.oAuthInitialRefresh, c.oAuthClientID, c.oAuthClientSecret, c.client,
+		c.epURL,
+		c.username,
+		c.password,
+		c.oAuthInitialRefresh,
+		c.oAuthClientID,
+		c.oAuthClientSecret,
+		c.clientCredentialsGrant,
+		wrapTransportWithMiddlewares(c.client, c.middlewares),
 	)
 
 	if c.newOAuthTokenCallback != nil {
 		c.authProvider.newOAuthTokenCallback = c.newOAuthTokenCallback
 	}
 
+	c.authProvider.logger = c.logger
+	c.authProvider.tracer = c.tracer
+	c.authProvider.observer = c.observer
+	c.authProvider.tokenSource = c.tokenSource
+	c.authProvider.tokenStore = c.tokenStore
+	c.authProvider.refreshMargin = c.tokenRefreshMargin
+	c.authProvider.refreshErrorCallback = c.tokenRefreshErrorCallback
+
 	return c, nil
 }
 
+// cacheIdentity returns a stable identifier of the user or application the client authenticates as,
+// or an empty string if it isn't known.
+func (c *Client) cacheIdentity() string {
+	switch {
+	case c.tokenSource != nil:
+		return ""
+	case c.clientCredentialsGrant:
+		return "client:" + c.oAuthClientID
+	case c.username != "":
+		return "user:" + c.username
+	default:
+		return ""
+	}
+}
+
+// configureClient returns a Client with the default configuration, customized with the given options.
+func configureClient(opts []ClientOption) *Client {
+	c := &Client{
+		endpoint:                  defaultEndpoint,
+		oAuthClientID:             defaultOAuthClientID,
+		client:                    new(http.Client),
+		headers:                   map[string]string{"User-Agent": defaultUserAgent},
+		throttleMaxAutoRetryDelay: defaultThrottleMaxAutoRetryDelay,
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+
+	return c
+}
+
 // ThrottleDeadline return the time request should be retried.
 func (c *Client) ThrottleDeadline() time.Time {
 	c.l.Lock()
@@ -119,10 +181,20 @@ func (c *Client) GetToken(ctx context.Context) (*oauth2.Token, error) {
 }
 
 // Logout revokes the OAuth token, preventing it from being reused.
+// The token is revoked through its refresh token when it has one, even if its access token has expired.
+// If a token store is configured, the stored token is revoked and then deleted,
+// even when no request has been sent yet, or when the revocation fails.
 func (c *Client) Logout(ctx context.Context) error {
 	return c.authProvider.logout(ctx, c.endpoint)
 }
 
+// Close stops the background renewal of the OAuth token enabled by WithBackgroundTokenRefresh, if any,
+// without revoking the token. The client can still be used afterward,
+// but its token is then only renewed when a request needs it.
+func (c *Client) Close() {
+	c.authProvider.closeRefresh()
+}
+
 // Get the resource with the given id, with only the given fields, if not nil.
 func (c *Client) Get(ctx context.Context, resource Resource, id string, fields ...string) (json.RawMessage, error) {
 	reqURI, err := url.JoinPath(resource, id, "/")
@@ -177,8 +249,9 @@ func (c *Client) Count(ctx context.Context, resource Resource, params url.Values
 
 // Iterator returns a single-use iterator over resources that match given params.
 // The page size is set to 2500 by default, but can be defined by setting `page_size` in params.
-func (c *Client) Iterator(resource Resource, params url.Values) Iterator {
-	return newIterator(c, resource, params)
+// Options such as WithParallelFetch() can be given to customize the iteration.
+func (c *Client) Iterator(resource Resource, params url.Values, opts ...IteratorOption) Iterator {
+	return newIterator(c, resource, params, opts...)
 }
 
 // Create a resource with the given body, which may be any value
@@ -227,62 +300,275 @@ func (c *Client) Delete(ctx context.Context, resource Resource, id string) error
 
 // Do is a lower-level method to build and execute the request according to the given parameters.
 // It returns the response status code and body content, or any error that occurred.
+// Requests failing with a transient error are retried according to the policy set with WithRetryPolicy, if any.
+// If the client is throttled, an error is returned without sending the request,
+// unless WithThrottleQueue has been used and the remaining delay is below the max auto retry delay.
+// If WithRequestDeduplication has been used, concurrent identical GET requests share a single execution.
 //
 // When possible, prefer the higher-level Get, GetPage, Iterator, Create, Update and Delete.
 func (c *Client) Do(
 	ctx context.Context, method, reqURI string, params url.Values, authenticated bool, body io.Reader,
 ) (int, []byte, error) {
-	if delay := time.Until(c.throttleDeadline); delay > 0 {
-		return 0, nil, &ThrottleError{
-			APIError: &APIError{
-				ReqPath:    reqURI,
-				StatusCode: http.StatusTooManyRequests,
-				Message:    fmt.Sprintf("Too many requests, need to wait for %s", delay),
-			},
-			Delay: delay,
-		}
+	return c.doWithState(ctx, method, reqURI, params, authenticated, body, new(requestState))
+}
+
+// doStream executes the request like Do(), but returns the response of successful requests
+// with its body unread, so it can be decoded while being received.
+// It is up to the caller to close the response body.
+func (c *Client) doStream(
+	ctx context.Context, method, reqURI string, params url.Values, authenticated bool,
+) (*http.Response, error) {
+	state := &requestState{stream: true}
+
+	_, _, err := c.doWithState(ctx, method, reqURI, params, authenticated, nil, state)
+	if err != nil {
+		return nil, err
 	}
 
+	return state.streamResp, nil
+}
+
+// doWithState builds and executes the request, keeping track of its execution in the given state.
+func (c *Client) doWithState(
+	ctx context.Context,
+	method, reqURI string,
+	params url.Values,
+	authenticated bool,
+	body io.Reader,
+	state *requestState,
+) (int, []byte, error) {
 	req, err := c.ParseRequest(method, reqURI, nil, params, body)
 	if err != nil {
 		return 0, nil, err
 	}
 
-	statusCode, respBody, err := c.doWithErrorHandling(ctx, req, authenticated)
+	resource := resourceFromPath(req.URL.Path)
+
+	ctx, span := startSpan(ctx, c.tracer, method+" "+resource, trace.SpanKindInternal,
+		attribute.String(attrResource, resource),
+		attribute.String(attrMethod, method),
+	)
+
+	var (
+		statusCode int
+		respBody   []byte
+	)
+
+	if key, ok := flightKey(req, authenticated); ok && c.requestGroup != nil && !state.stream {
+		sharedState := new(requestState)
+
+		statusCode, respBody, err = c.requestGroup.do(ctx, key, func(ctx context.Context) (int, []byte, error) {
+			return c.doWithRetries(ctx, req, reqURI, authenticated, sharedState)
+		})
+
+		// If our context is done, the shared request may still be running.
+		if ctx.Err() == nil {
+			*state = *sharedState
+		}
+	} else {
+		statusCode, respBody, err = c.doWithRetries(ctx, req, reqURI, authenticated, state)
+	}
+
+	endSpan(span, statusCode, err,
+		attribute.Int(attrRetryCount, max(state.attempt-1, 0)),
+		attribute.Float64(attrThrottleWait, state.throttleWait.Seconds()),
+	)
+
+	return statusCode, respBody, err
+}
+
+// requestState holds information about the execution of a request across its retries.
+type requestState struct {
+	// The number of the current attempt, starting from 1.
+	attempt int
+	// The total time spent waiting because of throttling.
+	throttleWait time.Duration
+	// If stream is true, the body of a successful response isn't read,
+	// and the response is stored in streamResp instead.
+	stream     bool
+	streamResp *http.Response
+}
+
+// doWithRetries executes the given request, after waiting for the end of throttling if needed,
+// and retries it according to the retry policy of the client.
+func (c *Client) doWithRetries(
+	ctx context.Context, req *http.Request, reqURI string, authenticated bool, state *requestState,
+) (int, []byte, error) {
+	if delay := time.Until(c.ThrottleDeadline()); delay > 0 {
+		if !c.queueThrottled || delay > c.throttleMaxAutoRetryDelay {
+			return 0, nil, &ThrottleError{
+				APIError: &APIError{
+					ReqPath:    reqURI,
+					StatusCode: http.StatusTooManyRequests,
+					Message:    fmt.Sprintf("Too many requests, need to wait for %s", delay),
+				},
+				Delay: delay,
+			}
+		}
+
+		orDiscard(c.logger).LogAttrs(ctx, slog.LevelInfo, "Waiting for the end of throttling",
+			slog.String("path", reqURI), slog.Duration("delay", delay))
+
+		start := time.Now()
+		next, err := c.throttleQueue.wait(ctx, c.ThrottleDeadline)
+		state.throttleWait += time.Since(start)
+
+		if err != nil {
+			return 0, nil, err
+		}
+
+		// In case the request fails before being sent, the next waiter mustn't be stuck.
+		defer next()
+
+		ctx = contextWithThrottleRelease(ctx, next)
+	}
+
+	state.attempt = 1
+	statusCode, respBody, err := c.doWithThrottleRetry(ctx, req, authenticated, state)
+
+	for c.retryPolicy.shouldRetry(state.attempt, err) {
+		backoff := c.retryPolicy.backoff(state.attempt)
+
+		orDiscard(c.logger).LogAttrs(ctx, slog.LevelInfo, "Retrying request after a transient error",
+			slog.String("path", req.URL.Path),
+			slog.Int("attempt", state.attempt),
+			slog.Duration("backoff", backoff),
+			slog.Any("error", err),
+		)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return statusCode, nil, ctx.Err() //nolint:wrapcheck
+		}
+
+		var retryReq *http.Request
+
+		retryReq, err = cloneRequest(ctx, req)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		state.attempt++
+
+		orNoop(c.observer).RequestRetried(resourceFromPath(req.URL.Path), req.Method)
+
+		statusCode, respBody, err = c.doWithThrottleRetry(ctx, retryReq, authenticated, state)
+	}
+
+	return statusCode, respBody, err
+}
+
+// doWithThrottleRetry executes the given request, and sends it once again
+// if it has been throttled for less than the max auto retry delay.
+func (c *Client) doWithThrottleRetry(
+	ctx context.Context, req *http.Request, authenticated bool, state *requestState,
+) (int, []byte, error) {
+	statusCode, respBody, err := c.doAndReport(ctx, req, authenticated, state)
 	if throttleErr := new(ThrottleError); errors.As(err, &throttleErr) {
 		if throttleErr.Delay <= c.throttleMaxAutoRetryDelay {
+			orDiscard(c.logger).LogAttrs(ctx, slog.LevelWarn, "Request throttled, waiting before sending it again",
+				slog.String("path", req.URL.Path), slog.Duration("delay", throttleErr.Delay))
+
+			state.throttleWait += throttleErr.Delay
+
 			select {
 			case <-time.After(throttleErr.Delay):
 			case <-ctx.Done():
 				return statusCode, nil, ctx.Err() //nolint:wrapcheck
 			}
 
-			statusCode, respBody, err = c.doWithErrorHandling(ctx, req.Clone(ctx), authenticated)
+			var retryReq *http.Request
+
+			retryReq, err = cloneRequest(ctx, req)
+			if err != nil {
+				return 0, nil, err
+			}
+
+			orNoop(c.observer).RequestRetried(resourceFromPath(req.URL.Path), req.Method)
+
+			statusCode, respBody, err = c.doAndReport(ctx, retryReq, authenticated, state)
 		}
 	}
 
 	return statusCode, respBody, err
 }
 
+// doAndReport executes the given request with error handling,
+// and reports its outcome to the logger and the observer of the client.
+func (c *Client) doAndReport(
+	ctx context.Context, req *http.Request, authenticated bool, state *requestState,
+) (int, []byte, error) {
+	start := time.Now()
+
+	statusCode, respBody, err := c.doWithErrorHandling(ctx, req, authenticated, state)
+	duration := time.Since(start)
+
+	logRequest(ctx, orDiscard(c.logger), req, state.attempt, statusCode, respBody, duration, err)
+	orNoop(c.observer).RequestDone(resourceFromPath(req.URL.Path), req.Method, statusCode, duration, err)
+
+	return statusCode, respBody, err
+}
+
 // DoRequest sends the given request and returns the response or any error.
+// If a rate limit has been defined, the request will only be sent once allowed by the limiter.
 // If authenticated is true, the request will be sent with an Authorization header.
 // If the API returns a 401 status code, a new token will be fetched and the request will be sent once again.
+// For the request body to be sent again, it is read through the request's GetBody function;
+// if the request has none, its body is buffered beforehand.
 // It is up to the caller to close the response body.
 func (c *Client) DoRequest(ctx context.Context, req *http.Request, authenticated bool) (*http.Response, error) {
+	ctx, span := startSpan(ctx, c.tracer, "HTTP "+req.Method, trace.SpanKindClient,
+		attribute.String(attrResource, resourceFromPath(req.URL.Path)),
+		attribute.String(attrMethod, req.Method),
+		attribute.String(attrURLPath, req.URL.Path),
+	)
+
+	resp, err := c.doRequest(ctx, req, authenticated)
+	if err != nil {
+		endSpan(span, 0, err)
+
+		return nil, err
+	}
+
+	endSpan(span, resp.StatusCode, nil)
+
+	return resp, nil
+}
+
+func (c *Client) doRequest(ctx context.Context, req *http.Request, authenticated bool) (*http.Response, error) {
+	if authenticated {
+		err := makeBodyReplayable(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	resp, err := c.do(ctx, req, authenticated)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode == http.StatusUnauthorized && authenticated {
+	// External tokens can't be refetched, so the 401 is handled as any other error
+	if resp.StatusCode == http.StatusUnauthorized && authenticated && c.tokenSource == nil {
 		cleanupResponse(resp)
 
+		orDiscard(c.logger).LogAttrs(ctx, slog.LevelInfo, "Request unauthorized, fetching a new OAuth token",
+			slog.String("path", req.URL.Path))
+
 		err = c.authProvider.refetchToken(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to refetch token: %w", err)
 		}
 
-		resp, err = c.do(ctx, req.Clone(ctx), authenticated)
+		var retryReq *http.Request
+
+		retryReq, err = cloneRequest(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err = c.do(ctx, retryReq, authenticated)
 		if err != nil {
 			return nil, fmt.Errorf("request execution retry failed: %w", err)
 		}
@@ -293,6 +579,7 @@ func (c *Client) DoRequest(ctx context.Context, req *http.Request, authenticated
 
 // ParseRequest returns a new [*http.Request] according to the given values.
 // The URL may or may not contain a host, if not, the client's endpoint will be used as such.
+// The returned request has a GetBody function, so its body can be sent again when the request is retried.
 func (c *Client) ParseRequest(
 	method, url string, headers http.Header, params url.Values, body io.Reader,
 ) (*http.Request, error) {
@@ -311,11 +598,20 @@ func (c *Client) ParseRequest(
 
 	reqURL.RawQuery = q.Encode()
 
+	body, getBody, err := replayableBody(body)
+	if err != nil {
+		return nil, err
+	}
+
 	req, err := http.NewRequest(method, reqURL.String(), body) //nolint: lll,noctx // The context will be set by the request executor
 	if err != nil {
 		return nil, fmt.Errorf("can't parse request: %w", err)
 	}
 
+	if getBody != nil {
+		req.GetBody = getBody
+	}
+
 	if body != nil && headers.Get("Content-Type") == "" {
 		req.Header.Set("Content-Type", "application/json")
 	}
@@ -333,12 +629,20 @@ func (c *Client) ParseRequest(
 	return req, nil
 }
 
-func (c *Client) doWithErrorHandling(ctx context.Context, req *http.Request, authenticated bool) (int, []byte, error) {
+func (c *Client) doWithErrorHandling(
+	ctx context.Context, req *http.Request, authenticated bool, state *requestState,
+) (int, []byte, error) {
 	resp, err := c.DoRequest(ctx, req, authenticated)
 	if err != nil {
 		return 0, nil, fmt.Errorf("request execution failed: %w", err)
 	}
 
+	if state.stream && resp.StatusCode < 400 {
+		state.streamResp = resp
+
+		return resp.StatusCode, nil, nil
+	}
+
 	defer cleanupResponse(resp)
 
 	if resp.StatusCode >= 500 {
@@ -347,12 +651,12 @@ func (c *Client) doWithErrorHandling(ctx context.Context, req *http.Request, aut
 			StatusCode:  resp.StatusCode,
 			ContentType: resp.Header.Get("Content-Type"),
 			Message:     resp.Status,
-			Response:    readBodyStart(resp.Body),
+			Response:    readBodyStart(resp.Body, orDiscard(c.logger)),
 		}
 	}
 
 	if resp.StatusCode >= 400 {
-		bodyStart := readBodyStart(resp.Body)
+		bodyStart := readBodyStart(resp.Body, orDiscard(c.logger))
 		apiErr := APIError{
 			ReqPath:     req.URL.Path,
 			StatusCode:  resp.StatusCode,
@@ -378,7 +682,16 @@ func (c *Client) doWithErrorHandling(ctx context.Context, req *http.Request, aut
 				apiErr.Message = "Bad request:" + makeBadRequestMessage(respBody)
 			}
 		case http.StatusUnauthorized:
-			return resp.StatusCode, nil, buildAuthErrorFromBody(&apiErr)
+			authErr := buildAuthErrorFromBody(&apiErr)
+			if c.tokenSource != nil && authenticated {
+				if authErr.Err != nil {
+					authErr.Err = fmt.Errorf("%w: %w", ErrExternalTokenRejected, authErr.Err)
+				} else {
+					authErr.Err = ErrExternalTokenRejected
+				}
+			}
+
+			return resp.StatusCode, nil, authErr
 		case http.StatusNotFound:
 			apiErr.Err = fmt.Errorf("%w: %s", ErrResourceNotFound, req.URL.Path)
 		case http.StatusTooManyRequests:
@@ -389,10 +702,18 @@ func (c *Client) doWithErrorHandling(ctx context.Context, req *http.Request, aut
 				delay = time.Duration(delaySecond) * time.Second
 			}
 
+			deadline := time.Now().Add(delay)
+
 			c.l.Lock()
-			c.throttleDeadline = time.Now().Add(delay)
+			c.throttleDeadline = deadline
 			c.l.Unlock()
 
+			orNoop(c.observer).Throttled(resourceFromPath(req.URL.Path), delay, deadline)
+
+			if c.rateLimiter != nil {
+				c.rateLimiter.Throttled(delay)
+			}
+
 			apiErr.Message = fmt.Sprintf("Too many requests, need to wait for %s", delay)
 
 			return resp.StatusCode, nil, &ThrottleError{
@@ -425,6 +746,13 @@ func (c *Client) doWithErrorHandling(ctx context.Context, req *http.Request, aut
 }
 
 func (c *Client) do(ctx context.Context, req *http.Request, authenticated bool) (*http.Response, error) {
+	if c.rateLimiter != nil {
+		err := c.rateLimiter.Wait(ctx)
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+	}
+
 	if authenticated {
 		err := c.authProvider.injectHeader(ctx, req)
 		if err != nil {
@@ -432,7 +760,18 @@ func (c *Client) do(ctx context.Context, req *http.Request, authenticated bool)
 		}
 	}
 
-	resp, err := c.client.Do(req.WithContext(ctx))
+	if c.tracer != nil {
+		propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+
+	middlewares := c.middlewares
+	if c.responseCache != nil {
+		middlewares = append([]Middleware{cacheMiddleware(c.responseCache, c.cacheIdentity())}, middlewares...)
+	}
+
+	releaseThrottleQueue(ctx)
+
+	resp, err := chainMiddlewares(c.client.Do, middlewares)(req.WithContext(ctx))
 	if err != nil {
 		return nil, err //nolint:wrapcheck
 	}
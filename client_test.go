@@ -21,8 +21,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -30,7 +33,9 @@ import (
 
 var errUnreadable = errors.New("unreadable")
 
-func makeClientMockForDo(t *testing.T, handler mockHandler) (c *Client, requestCounter map[string]int) {
+func makeClientMockForDo(
+	t *testing.T, handler mockHandler, extraOpts ...ClientOption,
+) (c *Client, requestCounter map[string]int) {
 	t.Helper()
 
 	requestCounter = make(map[string]int)
@@ -43,7 +48,7 @@ func makeClientMockForDo(t *testing.T, handler mockHandler) (c *Client, requestC
 		},
 	}
 
-	c, err := NewClient(WithCredentials("u", ""), WithHTTPClient(clientMock))
+	c, err := NewClient(append([]ClientOption{WithCredentials("u", ""), WithHTTPClient(clientMock)}, extraOpts...)...)
 	if err != nil {
 		t.Fatal("Failed to initialize client:", err)
 	}
@@ -203,6 +208,125 @@ func TestClientDo(t *testing.T) {
 	})
 }
 
+func TestRequestBodyReplay(t *testing.T) {
+	t.Parallel()
+
+	const reqBody = `{"label":"replayed"}`
+
+	cases := []struct {
+		name        string
+		statusCodes []int
+		headers     http.Header
+		// makeBody returns the body to send; it defaults to a reader built by JSONReaderFrom.
+		makeBody func() io.Reader
+		opts     []ClientOption
+		// Whether to build the request ourselves and send it with DoRequest.
+		useDoRequest bool
+	}{
+		{
+			name:        "unauthorized",
+			statusCodes: []int{http.StatusUnauthorized, http.StatusOK},
+		},
+		{
+			name:        "throttled",
+			statusCodes: []int{http.StatusTooManyRequests, http.StatusOK},
+			headers:     http.Header{"Retry-After": {"0"}},
+		},
+		{
+			name:        "transient error",
+			statusCodes: []int{http.StatusServiceUnavailable, http.StatusOK},
+			opts:        []ClientOption{WithRetryPolicy(RetryPolicy{BaseBackoff: time.Millisecond})},
+		},
+		{
+			name:        "non-seekable reader",
+			statusCodes: []int{http.StatusUnauthorized, http.StatusOK},
+			makeBody: func() io.Reader {
+				return struct{ io.Reader }{strings.NewReader(reqBody)}
+			},
+		},
+		{
+			name:        "request without GetBody",
+			statusCodes: []int{http.StatusUnauthorized, http.StatusOK},
+			makeBody: func() io.Reader {
+				return struct{ io.Reader }{strings.NewReader(reqBody)}
+			},
+			useDoRequest: true,
+		},
+	}
+
+	for _, testCase := range cases {
+		tc := testCase
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var receivedBodies []string
+
+			requestCounter := make(map[string]int)
+			resourceHandler := func(r *http.Request) (int, []byte, error) {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Fatal("Failed to read request body:", err)
+				}
+
+				receivedBodies = append(receivedBodies, string(body))
+
+				return tc.statusCodes[len(receivedBodies)-1], []byte(`{}`), nil
+			}
+			clientMock := &http.Client{
+				Transport: &transportMock{
+					handlers: map[string]mockHandler{
+						tokenPath:       authMockHandler,
+						"/v1/resource/": resourceHandler,
+					},
+					counters: requestCounter,
+					headers:  map[string]http.Header{"/v1/resource/": tc.headers},
+				},
+			}
+
+			client, err := NewClient(append([]ClientOption{WithCredentials("u", ""), WithHTTPClient(clientMock)}, tc.opts...)...)
+			if err != nil {
+				t.Fatal("Failed to initialize client:", err)
+			}
+
+			var body io.Reader
+
+			if tc.makeBody != nil {
+				body = tc.makeBody()
+			} else {
+				body, err = JSONReaderFrom(map[string]string{"label": "replayed"})
+				if err != nil {
+					t.Fatal("Failed to make body:", err)
+				}
+			}
+
+			if tc.useDoRequest {
+				req, err := http.NewRequest(http.MethodPost, defaultEndpoint+"/v1/resource/", body) //nolint: noctx
+				if err != nil {
+					t.Fatal("Failed to make request:", err)
+				}
+
+				resp, err := client.DoRequest(context.Background(), req, true)
+				if err != nil {
+					t.Fatal("Failed to execute request:", err)
+				}
+
+				cleanupResponse(resp)
+			} else {
+				_, _, err = client.Do(context.Background(), http.MethodPost, "/v1/resource/", nil, true, body)
+				if err != nil {
+					t.Fatal("Failed to execute request:", err)
+				}
+			}
+
+			expectedBodies := []string{reqBody, reqBody}
+			if diff := cmp.Diff(expectedBodies, receivedBodies); diff != "" {
+				t.Fatalf("Unexpected request bodies (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 // equateErrorStr considers errors of the given type to be equal
 // if their string representations are equal.
 func equateErrorStr(errType string) cmp.Option {
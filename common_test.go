@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 )
 
 const httpResponseHeader = "HTTP/1.1 %d %s\r\n\n"
@@ -31,8 +32,11 @@ var errMockHandlerNotFound = errors.New("mock handler not found")
 type mockHandler func(r *http.Request) (statusCode int, body []byte, err error)
 
 type transportMock struct {
+	l        sync.Mutex
 	handlers map[string]mockHandler
 	counters map[string]int
+	// Optional headers to add to the responses, by request path.
+	headers map[string]http.Header
 }
 
 func (tm *transportMock) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -41,7 +45,9 @@ func (tm *transportMock) RoundTrip(req *http.Request) (*http.Response, error) {
 		return nil, fmt.Errorf("%w: %q", errMockHandlerNotFound, req.URL.Path)
 	}
 
+	tm.l.Lock()
 	tm.counters[req.URL.Path]++
+	tm.l.Unlock()
 
 	statusCode, body, err := handler(req)
 	if err != nil {
@@ -50,5 +56,14 @@ func (tm *transportMock) RoundTrip(req *http.Request) (*http.Response, error) {
 
 	respData := append([]byte(fmt.Sprintf(httpResponseHeader, statusCode, http.StatusText(statusCode))), body...)
 
-	return http.ReadResponse(bufio.NewReader(bytes.NewReader(respData)), req) //nolint:wrapcheck
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(respData)), req)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	for header, values := range tm.headers[req.URL.Path] {
+		resp.Header[header] = values
+	}
+
+	return resp, nil
 }
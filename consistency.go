@@ -0,0 +1,100 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"encoding/json"
+)
+
+// WithConsistencyCheck makes the iterator keep track of the IDs of the resources it returns,
+// to drop the duplicates which may appear when resources are created or deleted during the iteration.
+// Once all the pages have been read, the number of distinct resources returned is compared
+// with the count announced by the API. If they differ, or if the count changed between pages,
+// Iterator.Err() returns an [*InconsistentListingError].
+// The check isn't performed if the iteration is stopped early.
+// When resuming from a checkpoint (see WithCheckpoint), the resources returned before it are unknown,
+// so duplicates are only dropped within the resumed iteration, and only a count
+// changing between pages is reported.
+func WithConsistencyCheck() IteratorOption {
+	return func(iter *iterator) {
+		iter.consistency = &consistencyChecker{
+			seenIDs: make(map[string]struct{}),
+		}
+	}
+}
+
+// consistencyChecker drops the duplicated resources of a listing,
+// and checks the total of the remaining ones against the count announced by the API.
+type consistencyChecker struct {
+	seenIDs    map[string]struct{}
+	returned   int
+	duplicates int
+	// Whether the iteration has been resumed from a checkpoint,
+	// in which case the returned resources can't be compared with the count.
+	resumed bool
+
+	counted      bool
+	count        int
+	countChanged bool
+}
+
+// accept returns whether the given resource hasn't been seen yet.
+// Resources without an ID are always accepted.
+func (cc *consistencyChecker) accept(resource json.RawMessage) bool {
+	var withID struct {
+		ID json.RawMessage `json:"id"`
+	}
+
+	if err := json.Unmarshal(resource, &withID); err == nil && len(withID.ID) > 0 {
+		if _, seen := cc.seenIDs[string(withID.ID)]; seen {
+			cc.duplicates++
+
+			return false
+		}
+
+		cc.seenIDs[string(withID.ID)] = struct{}{}
+	}
+
+	cc.returned++
+
+	return true
+}
+
+// observeCount records the count of resources announced by the API with a page.
+func (cc *consistencyChecker) observeCount(count int) {
+	if cc.counted && count != cc.count {
+		cc.countChanged = true
+	}
+
+	cc.counted = true
+	cc.count = count
+}
+
+// check returns an error if the listing wasn't consistent.
+func (cc *consistencyChecker) check(resource Resource) error {
+	if (cc.resumed || cc.returned == cc.count) && !cc.countChanged {
+		return nil
+	}
+
+	return &InconsistentListingError{
+		Resource:      resource,
+		ExpectedCount: cc.count,
+		ReturnedCount: cc.returned,
+		Duplicates:    cc.duplicates,
+		CountChanged:  cc.countChanged,
+	}
+}
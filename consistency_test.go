@@ -0,0 +1,162 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// makePagesMockHandler returns a handler serving the given pages, by page number.
+func makePagesMockHandler(pages map[string]string) mockHandler {
+	return func(r *http.Request) (int, []byte, error) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+
+		return http.StatusOK, []byte(pages[page]), nil
+	}
+}
+
+func TestConsistencyCheck(t *testing.T) {
+	t.Parallel()
+
+	t.Run("duplicates dropped", func(t *testing.T) {
+		t.Parallel()
+
+		handler := makePagesMockHandler(map[string]string{
+			"1": `{"count": 5, "next": "/v1/metric/?page=2", "results": [{"id": 1}, {"id": 2}, {"id": 3}]}`,
+			"2": `{"count": 5, "next": null, "results": [{"id": 3}, {"id": 4}, {"id": 5}]}`,
+		})
+
+		client, _ := makeClientMockForIteration(t, handler)
+		iter := client.Iterator(ResourceMetric, url.Values{}, WithConsistencyCheck())
+
+		ids := collectIDs(t, iter)
+		if err := iter.Err(); err != nil {
+			t.Fatal("Iterator error:", err)
+		}
+
+		if diff := cmp.Diff([]int{1, 2, 3, 4, 5}, ids); diff != "" {
+			t.Fatalf("Unexpected IDs (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("resource skipped", func(t *testing.T) {
+		t.Parallel()
+
+		handler := makePagesMockHandler(map[string]string{
+			"1": `{"count": 4, "next": "/v1/metric/?page=2", "results": [{"id": 1}, {"id": 2}]}`,
+			"2": `{"count": 3, "next": null, "results": [{"id": 4}]}`,
+		})
+
+		client, _ := makeClientMockForIteration(t, handler)
+		iter := client.Iterator(ResourceMetric, url.Values{}, WithConsistencyCheck())
+
+		ids := collectIDs(t, iter)
+
+		if diff := cmp.Diff([]int{1, 2, 4}, ids); diff != "" {
+			t.Fatalf("Unexpected IDs (-want +got):\n%s", diff)
+		}
+
+		inconsistentErr := new(InconsistentListingError)
+		if !errors.As(iter.Err(), &inconsistentErr) {
+			t.Fatalf("Expected an InconsistentListingError, got %v", iter.Err())
+		}
+
+		expectedErr := &InconsistentListingError{
+			Resource:      ResourceMetric,
+			ExpectedCount: 3,
+			ReturnedCount: 3,
+			CountChanged:  true,
+		}
+		if diff := cmp.Diff(expectedErr, inconsistentErr); diff != "" {
+			t.Fatalf("Unexpected error (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("early stop", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := makeClientMockForIteration(t, makeMetricMockHandler(t, 10))
+		iter := client.Iterator(ResourceMetric, url.Values{"page_size": {"5"}}, WithConsistencyCheck())
+
+		for range iter.All(context.Background()) {
+			break
+		}
+
+		if err := iter.Err(); err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+	})
+
+	t.Run("resumed from checkpoint", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := makeClientMockForIteration(t, makeMetricMockHandler(t, 15))
+		checkpoint := Checkpoint{PageURL: "/v1/metric/?page=2&page_size=5", Index: 2}
+		iter := client.Iterator(ResourceMetric, nil, WithConsistencyCheck(), WithCheckpoint(checkpoint))
+
+		ids := collectIDs(t, iter)
+		if err := iter.Err(); err != nil {
+			t.Fatal("Iterator error:", err)
+		}
+
+		if diff := cmp.Diff([]int{8, 9, 10, 11, 12, 13, 14, 15}, ids); diff != "" {
+			t.Fatalf("Unexpected IDs (-want +got):\n%s", diff)
+		}
+
+		handler := makePagesMockHandler(map[string]string{
+			"2": `{"count": 6, "next": "/v1/metric/?page=3", "results": [{"id": 3}, {"id": 4}]}`,
+			"3": `{"count": 5, "next": null, "results": [{"id": 6}]}`,
+		})
+
+		client, _ = makeClientMockForIteration(t, handler)
+		checkpoint = Checkpoint{PageURL: "/v1/metric/?page=2", Index: 1}
+		iter = client.Iterator(ResourceMetric, nil, WithCheckpoint(checkpoint), WithConsistencyCheck())
+
+		collectIDs(t, iter)
+
+		inconsistentErr := new(InconsistentListingError)
+		if !errors.As(iter.Err(), &inconsistentErr) || !inconsistentErr.CountChanged {
+			t.Fatalf("Expected an InconsistentListingError with a count change, got %v", iter.Err())
+		}
+	})
+
+	t.Run("parallel fetch", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := makeClientMockForIteration(t, makeMetricMockHandler(t, 10))
+		iter := client.Iterator(
+			ResourceMetric, url.Values{"page_size": {"5"}}, WithParallelFetch(2, false), WithConsistencyCheck(),
+		)
+
+		if ids := collectIDs(t, iter); len(ids) != 10 {
+			t.Fatalf("Expected 10 resources, got %d", len(ids))
+		}
+
+		if err := iter.Err(); err != nil {
+			t.Fatal("Iterator error:", err)
+		}
+	})
+}
@@ -26,8 +26,55 @@ The initialization of a Client is done with the NewClient function,
 which can take a variable number of ClientOption parameters.
 The following options can be used to customize the Client:
 
-WithCredentials, WithBleemeoAccountHeader, WithOAuthClient, WithEndpoint,
-WithInitialOAuthRefreshToken, WithHTTPClient, WithNewOAuthTokenCallback and WithThrottleMaxAutoRetryDelay.
+WithCredentials, WithBleemeoAccountHeader, WithOAuthClient, WithClientCredentialsGrant,
+WithTokenSource, WithStaticToken, WithEndpoint,
+WithInitialOAuthRefreshToken, WithHTTPClient, WithNewOAuthTokenCallback, WithThrottleMaxAutoRetryDelay,
+WithRetryPolicy, WithRateLimit, WithRateLimiter, WithThrottleQueue, WithMiddleware, WithLogger,
+WithTracerProvider, WithObserver, WithResponseCache, WithRequestDeduplication, WithTokenStore
+and WithBackgroundTokenRefresh.
+
+With WithClientCredentialsGrant, the Client authenticates as the OAuth application given with WithOAuthClient,
+using the client_credentials grant, which suits automation better than the credentials of a user.
+
+A token obtained elsewhere, e.g. from a secret manager, can be given with WithTokenSource or WithStaticToken.
+Such tokens are used as is, and a request rejected by the API fails with an AuthError
+wrapping ErrExternalTokenRejected instead of retrieving a new token.
+
+Interactive tools can log users in through their browser with LoginWithBrowser,
+which implements the OAuth authorization code grant with PKCE, receiving the code on a loopback server.
+The returned token can then be saved in a TokenStore, or its refresh token given to WithInitialOAuthRefreshToken.
+
+Middlewares given with WithMiddleware wrap the execution of every request sent by the Client,
+including the OAuth token requests, and can be used to observe or alter requests and responses.
+
+A log/slog Logger given with WithLogger receives a record for each request sent by the Client,
+as well as records about throttling, retries and OAuth token retrievals.
+At debug level, the request and response bodies are also logged, truncated and with secrets redacted.
+
+When an OpenTelemetry TracerProvider is given with WithTracerProvider, a span is recorded
+for each call to Client.Do() and Client.DoRequest(), with child spans for OAuth token retrievals.
+The trace context is sent to the API with the W3C Trace Context headers.
+
+An Observer given with WithObserver is notified of the requests, retries, throttling,
+OAuth token retrievals and pages fetched by the Client.
+The metrics subpackage provides an Observer which exposes them as Prometheus metrics,
+without adding the Prometheus dependency to programs which don't use it.
+
+With WithResponseCache, the responses of GET requests are stored in a ResponseCache,
+such as the in-memory one returned by NewLRUCache, and sent again as conditional requests.
+When the API replies with a 304 status code, the cached body is returned.
+Cached entries are scoped to the identity the Client authenticates as,
+so a cache shared by several clients doesn't leak responses from one user to another.
+
+WithRequestDeduplication makes concurrent identical GET requests share a single execution,
+while each caller still honors the cancellation of its own context.
+
+With WithTokenStore, the OAuth token is persisted in a TokenStore, such as the FileTokenStore
+returned by NewFileTokenStore, so that it can be reused across runs instead of fetching a new one each time.
+
+WithBackgroundTokenRefresh makes the Client renew the OAuth token in the background shortly before it expires,
+instead of when a request needs it, so that requests aren't delayed by the renewal.
+Client.Close() stops the renewal once the Client is no longer used, without revoking the token.
 
 The Client allows different kinds of resource interactions:
 
@@ -61,9 +108,12 @@ and returns whether the Iterator is exhausted or not.
 Calling Iterator.At() returns the resource at the current cursor position,
 but must only be done if Iterator.Next() has been called just before and returned true.
 Iterator.Err() returns the error that occurred during the iteration, if any.
+Pages are decoded while being received, so only the current resource is held in memory.
 
 	iter := client.Iterator(...)
-	for iter.Next() {
+	defer iter.Close()
+
+	for iter.Next(ctx) {
 	   value := iter.At()
 	   // process value
 	}
@@ -72,6 +122,49 @@ Iterator.Err() returns the error that occurred during the iteration, if any.
 	   // process error
 	}
 
+The page being read is kept open between calls to Iterator.Next(), so if the iteration is stopped
+before Iterator.Next() returns false, Iterator.Close() must be called to release the page and its connection.
+Iterator.All() closes the Iterator when the loop is exited. The context given to Iterator.Next()
+only needs to last for that call: it stops the iteration if it's done while the call is running.
+
+Options can be given to Client.Iterator() to customize the iteration. With WithParallelFetch,
+the pages are fetched concurrently, based on the total count of resources returned with the first page.
+With WithPrefetch, the next page is fetched in the background while the current one is being processed.
+With WithConsistencyCheck, duplicated resources are dropped, and an InconsistentListingError is reported
+by Iterator.Err() if the listing wasn't a consistent snapshot of the resources.
+
+Iterator.Checkpoint() returns the position of an Iterator as a serializable Checkpoint,
+from which a new Iterator can resume with the WithCheckpoint option,
+e.g. to continue a listing after an error instead of restarting it from the first page.
+When combined with WithConsistencyCheck, the resources returned before the checkpoint are unknown,
+so only a count changing between the resumed pages is reported.
+
+The generic Iterate function returns an iter.Seq2 over the resources, each one decoded into a given type,
+yielding any error that occurs during the iteration. Collect gathers such a sequence into a slice.
+
+	for metric, err := range bleemeo.Iterate[Metric](ctx, client, bleemeo.ResourceMetric, params) {
+	   if err != nil {
+	      // process error
+	   }
+	   // process metric
+	}
+
+The GetInto, CreateInto, UpdateInto and IterateInto functions only request the fields of the type
+the resources are decoded into, as returned by FieldsOf from its json struct tags,
+so the fields parameter doesn't have to be kept in sync with the type.
+The models subpackage provides such types for the core resources, with typed endpoints to access them.
+
+# Queries
+
+A Query builds the parameters of a listing, to be given to Client.GetPage(), Client.Count() or Client.Iterator():
+
+	query := bleemeo.NewQuery(bleemeo.ResourceMetric).Active(true).In("agent", agentID1, agentID2).Fields("id", "label")
+	iter := client.Iterator(bleemeo.ResourceMetric, query.Values())
+
+Query.Validate() checks the filters against the fields known to be filterable for the resource,
+which can be extended with RegisterFilterableFields, to catch typos before sending requests.
+The check is best-effort, since the fields known by default come from a partial schema of the API.
+
 # Resources
 
 A Resource represents a datatype on the Bleemeo API, and can be used as a route to access it.
@@ -102,6 +195,14 @@ Special cases of an APIError are AuthError and ThrottleError.
 If a ThrottleError occurs when executing a request using any client method except Client.DoRequest(),
 and if the delay to wait is less than the one specified with WithThrottleMaxAutoRetryDelay (which defaults to 1min),
 the request will be retried without returning an error.
+Requests sent while the client is throttled fail immediately with a ThrottleError,
+unless WithThrottleQueue is used, in which case they wait for the throttle delay to be over.
+
+Requests failing with a transient error (a 502, 503 or 504 status code, or a network error)
+can also be retried with an exponential backoff, by providing a RetryPolicy with WithRetryPolicy.
+
+To avoid being throttled in the first place, a client-side rate limit can be defined with WithRateLimit.
+It is shared by all the goroutines using the Client, and slows down when the API throttles the client anyway.
 
 A JSONMarshalError may occur when trying to serialize some request content to JSON.
 A JSONUnmarshalError may occur when deserializing the response content from JSON.
@@ -33,10 +33,20 @@ var (
 	errTokenHasNoRefresh = errors.New("the OAuth token has no refresh")
 	// ErrNoAuthMeanProvided is returned when the client has no way to retrieve an OAuth token.
 	ErrNoAuthMeanProvided = errors.New("no authentication mean provided")
+	// ErrNoOAuthClientSecret is returned when the client_credentials grant is used without an OAuth client secret.
+	ErrNoOAuthClientSecret = errors.New("the client_credentials grant requires an OAuth client secret")
+	// ErrExternalTokenRejected is returned when the API rejects a token given with WithTokenSource or WithStaticToken,
+	// since the client can't retrieve a new one by itself.
+	ErrExternalTokenRejected = errors.New("the token provided to the client has been rejected")
 	// ErrTokenRevoke is returned when the logout operation has not been completed successfully.
 	ErrTokenRevoke = errors.New("failed to revoke token")
 	// ErrResourceNotFound is returned when the resource with the specified ID doesn't exist (HTTP status 404).
 	ErrResourceNotFound = errors.New("resource not found")
+	// ErrUnknownFilter is returned when validating a Query filtering on a field which isn't known to be filterable.
+	ErrUnknownFilter = errors.New("unknown filter")
+	// ErrCheckpointForeignURL is returned when resuming from a checkpoint
+	// whose page URL doesn't belong to the endpoint of the client.
+	ErrCheckpointForeignURL = errors.New("the checkpoint page URL doesn't belong to the client endpoint")
 )
 
 // JSONErrorDataKind indicates the type of data whose conversion failed.
@@ -49,6 +59,8 @@ const (
 	JsonErrorDataKind_404Details
 	JsonErrorDataKind_ResultPage
 	JsonErrorDataKind_RequestBody
+	JsonErrorDataKind_Resource
+	JsonErrorDataKind_Token
 )
 
 func (kind JSONErrorDataKind) String() string {
@@ -63,6 +75,10 @@ func (kind JSONErrorDataKind) String() string {
 		return "result page"
 	case JsonErrorDataKind_RequestBody:
 		return "request body"
+	case JsonErrorDataKind_Resource:
+		return "resource"
+	case JsonErrorDataKind_Token:
+		return "token"
 	default:
 		return fmt.Sprintf("unknown JsonErrorDataKind %d", kind)
 	}
@@ -129,6 +145,36 @@ type ThrottleError struct {
 	Delay time.Duration
 }
 
+// An InconsistentListingError is returned at the end of an iteration using the WithConsistencyCheck option,
+// when the listing wasn't a consistent snapshot of the resources,
+// e.g. because some of them have been created or deleted during the iteration.
+type InconsistentListingError struct {
+	Resource Resource
+	// The number of resources announced by the API with the last page.
+	ExpectedCount int
+	// The number of distinct resources returned by the iterator.
+	ReturnedCount int
+	// The number of duplicated resources which have been dropped.
+	Duplicates int
+	// Whether the number of resources announced by the API changed during the iteration.
+	CountChanged bool
+}
+
+func (inconsistentErr *InconsistentListingError) Error() string {
+	msg := fmt.Sprintf("inconsistent listing of %s: expected %d resources, got %d (%d duplicates dropped)",
+		inconsistentErr.Resource,
+		inconsistentErr.ExpectedCount,
+		inconsistentErr.ReturnedCount,
+		inconsistentErr.Duplicates,
+	)
+
+	if inconsistentErr.CountChanged {
+		msg += ", the count changed during the iteration"
+	}
+
+	return msg
+}
+
 type jsonError struct {
 	Err      error
 	DataKind JSONErrorDataKind
@@ -2,10 +2,10 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"log"
 
 	"github.com/bleemeo/bleemeo-go"
+	"github.com/bleemeo/bleemeo-go/models"
 )
 
 // Creating a dashboard named "My dashboard",
@@ -25,45 +25,22 @@ func main() {
 		}
 	}()
 
-	body := map[string]any{"name": "My dashboard"}
-
-	dashboard, err := client.Create(context.Background(), bleemeo.ResourceDashboard, body)
+	dashboard, err := models.Dashboards.Create(context.Background(), client, models.Dashboard{Name: "My dashboard"})
 	if err != nil {
 		log.Panicln("Error creating dashboard:", err)
 	}
 
-	var dashboardObj struct {
-		ID   string `json:"id"`
-		Name string `json:"name"`
-	}
-
-	err = json.Unmarshal(dashboard, &dashboardObj)
-	if err != nil {
-		log.Panicln("Error unmarshalling dashboard:", err)
-	}
-
-	log.Println("Successfully created dashboard:", dashboardObj)
-	log.Println("View it on https://panel.bleemeo.com/dashboard/" + dashboardObj.ID)
+	log.Println("Successfully created dashboard:", dashboard)
+	log.Println("View it on https://panel.bleemeo.com/dashboard/" + dashboard.ID)
 
-	widget, err := client.Create(
+	widget, err := models.Widgets.Create(
 		context.Background(),
-		bleemeo.ResourceWidget,
-		map[string]any{"dashboard": dashboardObj.ID, "title": "My widget", "graph": bleemeo.Graph_Text},
+		client,
+		models.Widget{DashboardID: dashboard.ID, Title: "My widget", Graph: models.Ptr(bleemeo.Graph_Text)},
 	)
 	if err != nil {
 		log.Panicln("Error creating widget:", err)
 	}
 
-	var widgetObj struct {
-		ID    string        `json:"id"`
-		Title string        `json:"title"`
-		Graph bleemeo.Graph `json:"graph"`
-	}
-
-	err = json.Unmarshal(widget, &widgetObj)
-	if err != nil {
-		log.Panicln("Error unmarshalling widget:", err)
-	}
-
-	log.Println("Successfully created widget:", widgetObj)
+	log.Println("Successfully created widget:", widget)
 }
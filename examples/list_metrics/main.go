@@ -2,10 +2,8 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"log"
-	"net/url"
 
 	"github.com/bleemeo/bleemeo-go"
 )
@@ -26,22 +24,28 @@ func main() {
 		}
 	}()
 
-	// Retrieving only the id and label of each metric:
-	// the fewer fields required, the faster the query.
-	iter := client.Iterator(bleemeo.ResourceMetric, url.Values{"fields": {"id,label"}, "active": {"True"}})
-	count := 0
-
 	type metricType struct {
 		ID    string `json:"id"`
 		Label string `json:"label"`
 	}
 
-	for iter.Next(context.Background()) {
-		var metricObj metricType
+	// Retrieving only the id and label of each metric:
+	// the fewer fields required, the faster the query.
+	params := bleemeo.NewQuery(bleemeo.ResourceMetric).Fields("id", "label").Active(true).Values()
+	count := 0
 
-		err = json.Unmarshal(iter.At(), &metricObj)
+	for metricObj, err := range bleemeo.Iterate[metricType](context.Background(), client, bleemeo.ResourceMetric, params) {
 		if err != nil {
-			log.Panicln("Failed to unmarshal metric:", err)
+			if authErr := new(bleemeo.AuthError); errors.As(err, &authErr) {
+				// An AuthError is also an APIError
+				log.Panicln("Authentication error:", authErr.ErrorCode, "/", authErr.Message)
+			}
+
+			if apiErr := new(bleemeo.APIError); errors.As(err, &apiErr) {
+				log.Panicln("API error:", apiErr.StatusCode, "-", apiErr.Message)
+			}
+
+			log.Panicln("Iteration error:", err)
 		}
 
 		count++
@@ -52,18 +56,5 @@ func main() {
 		}
 	}
 
-	if err = iter.Err(); err != nil {
-		if authErr := new(bleemeo.AuthError); errors.As(err, &authErr) {
-			// An AuthError is also an APIError
-			log.Panicln("Authentication error:", authErr.ErrorCode, "/", authErr.Message)
-		}
-
-		if apiErr := new(bleemeo.APIError); errors.As(err, &apiErr) {
-			log.Panicln("API error:", apiErr.StatusCode, "-", apiErr.Message)
-		}
-
-		log.Panicln("Iteration error:", err)
-	}
-
 	log.Printf("Successfully retrieved %d metrics from API\n", count)
 }
@@ -0,0 +1,147 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+//nolint:gochecknoglobals
+var fieldsCache sync.Map // map[reflect.Type][]string
+
+// FieldsOf returns the names of the JSON fields of T, as defined by its json struct tags,
+// including the fields of its embedded structs. T must be a struct or a pointer to a struct,
+// otherwise nil is returned, meaning that all the fields should be requested.
+func FieldsOf[T any]() []string {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	if fields, ok := fieldsCache.Load(typ); ok {
+		return fields.([]string) //nolint:forcetypeassert
+	}
+
+	var fields []string
+
+	seen := make(map[string]bool)
+	appendStructFields(typ, seen, &fields)
+	fieldsCache.Store(typ, fields)
+
+	return fields
+}
+
+// appendStructFields appends the JSON names of the fields of the given struct type,
+// following the rules of encoding/json regarding ignored and embedded fields.
+func appendStructFields(typ reflect.Type, seen map[string]bool, fields *[]string) {
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+
+			if fieldType.Kind() == reflect.Struct {
+				appendStructFields(fieldType, seen, fields)
+
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if !seen[name] {
+			seen[name] = true
+			*fields = append(*fields, name)
+		}
+	}
+}
+
+// decodeInto unmarshals the given resource into a T.
+func decodeInto[T any](raw json.RawMessage, err error) (T, error) {
+	var result T
+
+	if err != nil {
+		return result, err
+	}
+
+	if err = json.Unmarshal(raw, &result); err != nil {
+		return result, &JSONUnmarshalError{
+			jsonError: &jsonError{
+				Err:      err,
+				DataKind: JsonErrorDataKind_Resource,
+				Data:     raw,
+			},
+		}
+	}
+
+	return result, nil
+}
+
+// GetInto retrieves the resource with the given id, only requesting the fields of T, and decodes it into a T.
+func GetInto[T any](ctx context.Context, c *Client, resource Resource, id string) (T, error) {
+	return decodeInto[T](c.Get(ctx, resource, id, FieldsOf[T]()...))
+}
+
+// CreateInto creates a resource with the given body, and decodes the created resource into a T,
+// only requesting the fields of T.
+func CreateInto[T any](ctx context.Context, c *Client, resource Resource, body any) (T, error) {
+	return decodeInto[T](c.Create(ctx, resource, body, FieldsOf[T]()...))
+}
+
+// UpdateInto updates the resource with the given id with the given body,
+// and decodes the updated resource into a T, only requesting the fields of T.
+func UpdateInto[T any](ctx context.Context, c *Client, resource Resource, id string, body any) (T, error) {
+	return decodeInto[T](c.Update(ctx, resource, id, body, FieldsOf[T]()...))
+}
+
+// IterateInto is like Iterate, but only requests the fields of T.
+// The fields parameter, if present in params, is overridden.
+func IterateInto[T any](
+	ctx context.Context, c *Client, resource Resource, params url.Values, opts ...IteratorOption,
+) iter.Seq2[T, error] {
+	if fields := FieldsOf[T](); fields != nil {
+		params = cloneMap(params)
+		params.Set("fields", strings.Join(fields, ","))
+	}
+
+	return Iterate[T](ctx, c, resource, params, opts...)
+}
@@ -0,0 +1,133 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fieldsBase struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type fieldsExtra struct {
+	Item string `json:"item,omitempty"`
+}
+
+type fieldsModel struct {
+	fieldsBase
+	*fieldsExtra
+
+	Agent    string `json:"agent"`
+	Label    string `json:"label"` // Same as the embedded one
+	Ignored  string `json:"-"`
+	NoTag    bool
+	Named    fieldsBase `json:"named"`
+	internal string     //nolint:unused // Unexported fields must be ignored
+}
+
+func TestFieldsOf(t *testing.T) {
+	t.Parallel()
+
+	expectedFields := []string{"id", "label", "item", "agent", "NoTag", "named"}
+	if diff := cmp.Diff(expectedFields, FieldsOf[fieldsModel]()); diff != "" {
+		t.Fatalf("Unexpected fields (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(expectedFields, FieldsOf[*fieldsModel]()); diff != "" {
+		t.Fatalf("Unexpected fields for pointer (-want +got):\n%s", diff)
+	}
+
+	if fields := FieldsOf[map[string]any](); fields != nil {
+		t.Fatalf("Expected no fields for a map, got %v", fields)
+	}
+}
+
+func TestStructFieldsHelpers(t *testing.T) {
+	t.Parallel()
+
+	var requestedFields []string
+
+	requestCounter := make(map[string]int)
+	clientMock := &http.Client{
+		Transport: &transportMock{
+			handlers: map[string]mockHandler{
+				tokenPath: authMockHandler,
+				"/v1/metric/": func(r *http.Request) (int, []byte, error) {
+					requestedFields = append(requestedFields, r.URL.Query().Get("fields"))
+
+					return http.StatusOK, []byte(`{"results": [{"id": "m1", "label": "cpu_used", "agent": "a1"}]}`), nil
+				},
+				"/v1/metric/m1/": func(r *http.Request) (int, []byte, error) {
+					requestedFields = append(requestedFields, r.URL.Query().Get("fields"))
+
+					return http.StatusOK, []byte(`{"id": "m1", "label": "cpu_used", "agent": "a1"}`), nil
+				},
+			},
+			counters: requestCounter,
+		},
+	}
+
+	client, err := NewClient(WithCredentials("u", ""), WithHTTPClient(clientMock))
+	if err != nil {
+		t.Fatal("Failed to initialize client:", err)
+	}
+
+	type metric struct {
+		fieldsBase
+
+		Agent string `json:"agent"`
+	}
+
+	expectedMetric := metric{fieldsBase: fieldsBase{ID: "m1", Label: "cpu_used"}, Agent: "a1"}
+
+	got, err := GetInto[metric](context.Background(), client, ResourceMetric, "m1")
+	if err != nil {
+		t.Fatal("Failed to get metric:", err)
+	}
+
+	cmpOpts := cmp.AllowUnexported(metric{})
+
+	if diff := cmp.Diff(expectedMetric, got, cmpOpts); diff != "" {
+		t.Fatalf("Unexpected metric (-want +got):\n%s", diff)
+	}
+
+	params := url.Values{"fields": {"overridden"}, "active": {"True"}}
+
+	metrics, err := Collect(IterateInto[metric](context.Background(), client, ResourceMetric, params), 0)
+	if err != nil {
+		t.Fatal("Failed to list metrics:", err)
+	}
+
+	if diff := cmp.Diff([]metric{expectedMetric}, metrics, cmpOpts); diff != "" {
+		t.Fatalf("Unexpected metrics (-want +got):\n%s", diff)
+	}
+
+	if params.Get("fields") != "overridden" {
+		t.Fatal("The given params have been mutated")
+	}
+
+	if diff := cmp.Diff([]string{"id,label,agent", "id,label,agent"}, requestedFields); diff != "" {
+		t.Fatalf("Unexpected requested fields (-want +got):\n%s", diff)
+	}
+}
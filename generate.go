@@ -0,0 +1,23 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+// The resource constants, the enums, the filter metadata and the models are generated
+// from the OpenAPI schema checked in api/openapi.json, which is for now a partial copy
+// of the schema of the Bleemeo API.
+// The Go names which can't be derived from the schema are pinned in api/overrides.json.
+//go:generate go run ./internal/generate -schema api/openapi.json -overrides api/overrides.json -dir .
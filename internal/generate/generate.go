@@ -0,0 +1,628 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const fileHeader = `// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by internal/generate from the OpenAPI schema. DO NOT EDIT.
+
+`
+
+const modulePath = "github.com/bleemeo/bleemeo-go"
+
+var (
+	errUnsupportedSchema = errors.New("unsupported schema")
+	errUnknownRef        = errors.New("unknown schema reference")
+	errUnnamedEnumValue  = errors.New("enum value without label nor override")
+)
+
+// enumLabelRegexp matches the lines describing the labels of enum values,
+// which are appended to the description of enums by drf-spectacular, e.g. "* `2` - Critical".
+var enumLabelRegexp = regexp.MustCompile("(?m)^\\* `([^`]*)` - (.*)$") //nolint:gochecknoglobals
+
+// The query parameters of listings which aren't filters.
+var nonFilterParameters = []string{"page", "page_size", "fields", "ordering", "search"} //nolint:gochecknoglobals
+
+// Words which are written in upper case in Go names.
+var initialisms = map[string]bool{ //nolint:gochecknoglobals
+	"api": true, "aws": true, "cpu": true, "fqdn": true, "http": true, "id": true,
+	"ip": true, "os": true, "snmp": true, "tcp": true, "url": true, "uuid": true,
+}
+
+type generator struct {
+	doc       *document
+	overrides *overrides
+	// The packages imported by the file being generated.
+	imports map[string]bool
+}
+
+// generate returns the content of the generated files, by their path relative to the root of the module.
+// The Go names are derived from the schema, unless they are pinned by the given overrides.
+func generate(doc *document, ovr *overrides) (map[string][]byte, error) {
+	g := generator{doc: doc, overrides: ovr}
+	writers := map[string]func(buf *bytes.Buffer) error{
+		"resources.go":     g.writeResources,
+		"enums.go":         g.writeEnums,
+		"filters.go":       g.writeFilters,
+		"models/models.go": g.writeModels,
+	}
+	files := make(map[string][]byte, len(writers))
+
+	for path, write := range writers {
+		g.imports = make(map[string]bool)
+
+		body := new(bytes.Buffer)
+
+		if err := write(body); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		content, err := format.Source(g.withHeader(body.Bytes()))
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to format generated code: %w", path, err)
+		}
+
+		files[path] = content
+	}
+
+	return files, nil
+}
+
+// withHeader prepends the file header and the imports to the given body, which starts with the package clause.
+func (g *generator) withHeader(body []byte) []byte {
+	buf := bytes.NewBufferString(fileHeader)
+	pkgClause, rest, _ := bytes.Cut(body, []byte("\n"))
+
+	buf.Write(pkgClause)
+	buf.WriteString("\n")
+
+	if len(g.imports) > 0 {
+		imports := make([]string, 0, len(g.imports))
+		for imp := range g.imports {
+			imports = append(imports, imp)
+		}
+
+		// Standard library packages first, as goimports does
+		slices.SortFunc(imports, func(a, b string) int {
+			aStd, bStd := !strings.Contains(a, "."), !strings.Contains(b, ".")
+			if aStd != bStd {
+				if aStd {
+					return -1
+				}
+
+				return 1
+			}
+
+			return strings.Compare(a, b)
+		})
+
+		buf.WriteString("\nimport (\n")
+
+		for i, imp := range imports {
+			if i > 0 && strings.Contains(imp, ".") && !strings.Contains(imports[i-1], ".") {
+				buf.WriteString("\n")
+			}
+
+			fmt.Fprintf(buf, "\t%q\n", imp)
+		}
+
+		buf.WriteString(")\n")
+	}
+
+	buf.Write(rest)
+
+	return buf.Bytes()
+}
+
+type resourcePath struct {
+	goName string
+	value  string
+	item   *pathItem
+}
+
+// resourcePaths returns the paths which list a kind of resource, such as "/v1/agent/",
+// skipping the detail paths, the actions and the paths outside of the versioned API.
+func (g *generator) resourcePaths() []resourcePath {
+	var paths []resourcePath
+
+	for _, e := range g.doc.Paths {
+		segments := strings.Split(strings.Trim(e.Name, "/"), "/")
+		if len(segments) != 2 || !isVersion(segments[0]) || strings.Contains(e.Name, "{") {
+			continue
+		}
+
+		value := strings.TrimPrefix(e.Name, "/")
+
+		goName, ok := g.overrides.Resources[e.Name]
+		if !ok {
+			goName = "Resource" + exportedName(segments[1])
+		}
+
+		paths = append(paths, resourcePath{goName: goName, value: value, item: e.Value})
+	}
+
+	return paths
+}
+
+func (g *generator) writeResources(buf *bytes.Buffer) error {
+	buf.WriteString("package bleemeo\n\n")
+	buf.WriteString("// A Resource represents a route to a model on the Bleemeo API.\n")
+	buf.WriteString("type Resource = string\n\n")
+	buf.WriteString("// Available resources on the Bleemeo API.\n")
+	buf.WriteString("const (\n")
+
+	for _, path := range g.resourcePaths() {
+		fmt.Fprintf(buf, "\t%s Resource = %q\n", path.goName, path.value)
+	}
+
+	buf.WriteString(")\n")
+
+	return nil
+}
+
+func (g *generator) writeEnums(buf *bytes.Buffer) error {
+	buf.WriteString("package bleemeo\n")
+
+	for _, e := range g.doc.Components.Schemas {
+		if len(e.Value.Enum) == 0 {
+			continue
+		}
+
+		if err := g.writeEnum(buf, e.Name, e.Value); err != nil {
+			return fmt.Errorf("enum %s: %w", e.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// writeEnum writes the type and the constants of the given enum schema.
+// The names of the constants are derived from the labels of the values, or from the values
+// themselves if they have no label, unless they are pinned by the overrides.
+func (g *generator) writeEnum(buf *bytes.Buffer, schemaName string, s *schema) error {
+	var goType string
+
+	name := g.typeName(schemaName)
+	labels, description := enumLabels(s.Description)
+
+	switch s.Type {
+	case "string":
+		goType = "string"
+	case "integer":
+		goType = "int"
+	default:
+		return fmt.Errorf("%w: enum of type %q", errUnsupportedSchema, s.Type)
+	}
+
+	buf.WriteString("\n")
+	writeComment(buf, "", description)
+	fmt.Fprintf(buf, "type %s %s\n\nconst (\n", name, goType)
+
+	for _, raw := range s.Enum {
+		key, value := string(raw), string(raw)
+
+		if goType == "string" {
+			if err := json.Unmarshal(raw, &key); err != nil {
+				return fmt.Errorf("value %s: %w", raw, err)
+			}
+
+			value = strconv.Quote(key)
+		}
+
+		varName, ok := g.overrides.EnumValues[schemaName][key]
+		if !ok {
+			label, hasLabel := labels[key]
+
+			switch {
+			case hasLabel:
+				varName = exportedName(label)
+			case goType == "string":
+				varName = exportedName(key)
+			}
+		}
+
+		if varName == "" || !unicode.IsLetter([]rune(varName)[0]) {
+			return fmt.Errorf("%w: %s", errUnnamedEnumValue, raw)
+		}
+
+		fmt.Fprintf(buf, "\t%s_%s %s = %s\n", name, varName, name, value)
+	}
+
+	buf.WriteString(")\n")
+
+	return nil
+}
+
+func (g *generator) writeFilters(buf *bytes.Buffer) error {
+	buf.WriteString("package bleemeo\n\n")
+	buf.WriteString("// defaultFilterableFields returns the fields known from the schema to be usable to filter")
+	buf.WriteString(" the listing of each resource.\n")
+	buf.WriteString("func defaultFilterableFields() map[Resource][]string {\n")
+	buf.WriteString("\treturn map[Resource][]string{\n")
+
+	for _, path := range g.resourcePaths() {
+		if path.item.Get == nil {
+			continue
+		}
+
+		var filters []string
+
+		for _, param := range path.item.Get.Parameters {
+			if param.In == "query" && !slices.Contains(nonFilterParameters, param.Name) {
+				filters = append(filters, strconv.Quote(param.Name))
+			}
+		}
+
+		if len(filters) > 0 {
+			fmt.Fprintf(buf, "\t\t%s: {%s},\n", path.goName, strings.Join(filters, ", "))
+		}
+	}
+
+	buf.WriteString("\t}\n}\n")
+
+	return nil
+}
+
+func (g *generator) writeModels(buf *bytes.Buffer) error {
+	buf.WriteString("package models\n")
+
+	var (
+		endpoints [][2]string
+		// The schemas of the pages returned by listings, which aren't models.
+		pages = make(map[string]bool)
+	)
+
+	for _, path := range g.resourcePaths() {
+		model, pageRef, err := g.listedModel(path.item)
+		if err != nil {
+			return fmt.Errorf("path %s: %w", path.value, err)
+		}
+
+		if model != "" {
+			endpoints = append(endpoints, [2]string{model, path.goName})
+		}
+
+		if pageRef != "" {
+			pages[refName(pageRef)] = true
+		}
+	}
+
+	for _, e := range g.doc.Components.Schemas {
+		if len(e.Value.Enum) > 0 || (e.Value.Type != "object" && len(e.Value.AllOf) == 0) || pages[e.Name] {
+			continue
+		}
+
+		if err := g.writeModel(buf, e.Name, e.Value); err != nil {
+			return fmt.Errorf("model %s: %w", e.Name, err)
+		}
+	}
+
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	g.imports[modulePath] = true
+
+	buf.WriteString("\n// Typed endpoints of the resources which have a model.\n//\n//nolint:gochecknoglobals\nvar (\n")
+
+	for _, endpoint := range endpoints {
+		fmt.Fprintf(buf, "\t%ss = Endpoint[%s]{Resource: bleemeo.%s}\n", endpoint[0], endpoint[0], endpoint[1])
+	}
+
+	buf.WriteString(")\n")
+
+	return nil
+}
+
+func (g *generator) writeModel(buf *bytes.Buffer, schemaName string, s *schema) error {
+	var (
+		embedded   []string
+		properties orderedMap[*schema]
+	)
+
+	for _, part := range append(slices.Clone(s.AllOf), s) {
+		if part.Ref != "" {
+			if _, err := g.resolve(part.Ref); err != nil {
+				return err
+			}
+
+			embedded = append(embedded, g.typeName(refName(part.Ref)))
+
+			continue
+		}
+
+		properties = append(properties, part.Properties...)
+	}
+
+	buf.WriteString("\n")
+	writeComment(buf, "", s.Description)
+	fmt.Fprintf(buf, "type %s struct {\n", g.typeName(schemaName))
+
+	for _, embeddedName := range embedded {
+		fmt.Fprintf(buf, "\t%s\n", embeddedName)
+	}
+
+	if len(embedded) > 0 && len(properties) > 0 {
+		buf.WriteString("\n")
+	}
+
+	for _, prop := range properties {
+		goType, err := g.goType(prop.Value)
+		if err != nil {
+			return fmt.Errorf("property %s: %w", prop.Name, err)
+		}
+
+		if g.zeroIsMeaningful(prop.Value) {
+			goType = "*" + goType
+		}
+
+		fieldName, ok := g.overrides.Fields[schemaName+"."+prop.Name]
+		if !ok {
+			fieldName = exportedName(prop.Name)
+		}
+
+		// Unset fields are omitted, so that models can be used as the body of requests.
+		omitOption := "omitempty"
+		if goType == "time.Time" || g.isObject(prop.Value) {
+			omitOption = "omitzero"
+		}
+
+		writeComment(buf, "\t", prop.Value.Description)
+		fmt.Fprintf(buf, "\t%s %s `json:%q`\n", fieldName, goType, prop.Name+","+omitOption)
+	}
+
+	buf.WriteString("}\n")
+
+	return nil
+}
+
+// goType returns the Go type of the values described by the given schema, as used in the models package.
+func (g *generator) goType(s *schema) (string, error) {
+	// A reference with sibling keywords, such as readOnly or nullable, is wrapped in an allOf.
+	if wrapped := unwrap(s); wrapped != s {
+		goType, err := g.goType(wrapped)
+		if err == nil && s.Nullable {
+			goType = "*" + goType
+		}
+
+		return goType, err
+	}
+
+	if s.Ref != "" {
+		target, err := g.resolve(s.Ref)
+		if err != nil {
+			return "", err
+		}
+
+		name := g.typeName(refName(s.Ref))
+		if len(target.Enum) > 0 {
+			g.imports[modulePath] = true
+
+			return "bleemeo." + name, nil
+		}
+
+		return name, nil
+	}
+
+	var goType string
+
+	switch {
+	case s.Type == "string" && s.Format == "date-time":
+		g.imports["time"] = true
+		goType = "time.Time"
+	case s.Type == "string":
+		goType = "string"
+	case s.Type == "integer":
+		goType = "int"
+	case s.Type == "number":
+		goType = "float64"
+	case s.Type == "boolean":
+		goType = "bool"
+	case s.Type == "object" && s.AdditionalProperties != nil && len(s.Properties) == 0:
+		valueType, err := g.goType(s.AdditionalProperties)
+		if err != nil {
+			return "", err
+		}
+
+		return "map[string]" + valueType, nil
+	case s.Type == "array" && s.Items != nil:
+		itemType, err := g.goType(s.Items)
+		if err != nil {
+			return "", err
+		}
+
+		return "[]" + itemType, nil
+	case s.Type == "" && len(s.Properties) == 0:
+		return "any", nil
+	default:
+		return "", fmt.Errorf("%w: type %q", errUnsupportedSchema, s.Type)
+	}
+
+	if s.Nullable {
+		goType = "*" + goType
+	}
+
+	return goType, nil
+}
+
+// zeroIsMeaningful returns whether the zero value of the given property is a valid value,
+// such as false or 0, which couldn't be told apart from an unset field.
+// The fields of such writable properties are pointers, so that they're only sent when set.
+func (g *generator) zeroIsMeaningful(s *schema) bool {
+	if s.ReadOnly || s.Nullable {
+		return false
+	}
+
+	target := unwrap(s)
+	if target.Ref != "" {
+		resolved, err := g.resolve(target.Ref)
+		if err != nil {
+			return false
+		}
+
+		target = resolved
+	}
+
+	return target.Type == "boolean" || target.Type == "integer" || target.Type == "number"
+}
+
+// isObject returns whether the given property holds a model, which is a struct.
+func (g *generator) isObject(s *schema) bool {
+	target := unwrap(s)
+	if target.Ref == "" || s.Nullable {
+		return false
+	}
+
+	resolved, err := g.resolve(target.Ref)
+
+	return err == nil && len(resolved.Enum) == 0
+}
+
+// unwrap returns the schema wrapped in the allOf of the given one, if it only wraps a single schema.
+func unwrap(s *schema) *schema {
+	if len(s.AllOf) == 1 && s.Ref == "" && s.Type == "" && len(s.Properties) == 0 {
+		return s.AllOf[0]
+	}
+
+	return s
+}
+
+// listedModel returns the name of the model listed by the given path, if its response is described,
+// and the reference to the schema of the page if it isn't inlined.
+func (g *generator) listedModel(item *pathItem) (model, pageRef string, err error) {
+	if item.Get == nil || item.Get.Responses["200"] == nil {
+		return "", "", nil
+	}
+
+	content, ok := item.Get.Responses["200"].Content["application/json"]
+	if !ok || content.Schema == nil {
+		return "", "", nil
+	}
+
+	page := content.Schema
+	if page.Ref != "" {
+		pageRef = page.Ref
+
+		page, err = g.resolve(pageRef)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	results, ok := page.Properties.get("results")
+	if !ok || results.Items == nil || results.Items.Ref == "" {
+		return "", pageRef, nil
+	}
+
+	if _, err = g.resolve(results.Items.Ref); err != nil {
+		return "", "", err
+	}
+
+	return g.typeName(refName(results.Items.Ref)), pageRef, nil
+}
+
+func (g *generator) resolve(ref string) (*schema, error) {
+	s, ok := g.doc.Components.Schemas.get(refName(ref))
+	if !ok {
+		return nil, fmt.Errorf("%w %q", errUnknownRef, ref)
+	}
+
+	return s, nil
+}
+
+// typeName returns the Go name of the type defined by the schema with the given name.
+func (g *generator) typeName(schemaName string) string {
+	if name, ok := g.overrides.Types[schemaName]; ok {
+		return name
+	}
+
+	return exportedName(schemaName)
+}
+
+// exportedName converts a snake case name or a label to an exported Go name,
+// e.g. "agent_fqdn" to "AgentFQDN" and "On demand" to "OnDemand".
+func exportedName(name string) string {
+	var sb strings.Builder
+
+	isSeparator := func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }
+
+	for _, part := range strings.FieldsFunc(name, isSeparator) {
+		if initialisms[strings.ToLower(part)] {
+			sb.WriteString(strings.ToUpper(part))
+		} else {
+			sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+
+	return sb.String()
+}
+
+// isVersion returns whether the given path segment is an API version, such as "v1".
+func isVersion(segment string) bool {
+	_, err := strconv.Atoi(strings.TrimPrefix(segment, "v"))
+
+	return strings.HasPrefix(segment, "v") && err == nil
+}
+
+// enumLabels extracts the labels of the enum values from the given description,
+// and returns them by value along with the rest of the description.
+func enumLabels(description string) (labels map[string]string, rest string) {
+	labels = make(map[string]string)
+
+	for _, match := range enumLabelRegexp.FindAllStringSubmatch(description, -1) {
+		labels[match[1]] = strings.TrimSpace(match[2])
+	}
+
+	return labels, strings.TrimSpace(enumLabelRegexp.ReplaceAllString(description, ""))
+}
+
+func writeComment(buf *bytes.Buffer, indent, text string) {
+	for line := range strings.Lines(strings.TrimSpace(text)) {
+		fmt.Fprintf(buf, "%s// %s", indent, line)
+	}
+
+	if text != "" {
+		buf.WriteString("\n")
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var update = flag.Bool("update", false, "update the golden files") //nolint:gochecknoglobals
+
+// goldenPath returns the path of the golden file of the given generated file.
+func goldenPath(path string) string {
+	return filepath.Join("testdata", strings.ReplaceAll(path, "/", "_")+".golden")
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	doc, err := loadDocument(filepath.Join("testdata", "schema.json"))
+	if err != nil {
+		t.Fatal("Failed to load schema:", err)
+	}
+
+	ovr, err := loadOverrides(filepath.Join("testdata", "overrides.json"))
+	if err != nil {
+		t.Fatal("Failed to load overrides:", err)
+	}
+
+	files, err := generate(doc, ovr)
+	if err != nil {
+		t.Fatal("Failed to generate code:", err)
+	}
+
+	for path, content := range files {
+		if *update {
+			if err = os.WriteFile(goldenPath(path), content, 0o600); err != nil {
+				t.Fatal("Failed to update golden file:", err)
+			}
+
+			continue
+		}
+
+		expected, err := os.ReadFile(goldenPath(path))
+		if err != nil {
+			t.Fatal("Failed to read golden file:", err)
+		}
+
+		if diff := cmp.Diff(string(expected), string(content)); diff != "" {
+			t.Errorf("Unexpected content for %s (-want +got):\n%s", path, diff)
+		}
+	}
+}
+
+// TestGeneratedFilesUpToDate ensures that the generated files of the module
+// have been regenerated after the last change of the schema or of the generator.
+func TestGeneratedFilesUpToDate(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.Join("..", "..")
+
+	doc, err := loadDocument(filepath.Join(root, "api", "openapi.json"))
+	if err != nil {
+		t.Fatal("Failed to load schema:", err)
+	}
+
+	ovr, err := loadOverrides(filepath.Join(root, "api", "overrides.json"))
+	if err != nil {
+		t.Fatal("Failed to load overrides:", err)
+	}
+
+	files, err := generate(doc, ovr)
+	if err != nil {
+		t.Fatal("Failed to generate code:", err)
+	}
+
+	for path, content := range files {
+		current, err := os.ReadFile(filepath.Join(root, path))
+		if err != nil {
+			t.Fatal("Failed to read generated file:", err)
+		}
+
+		if diff := cmp.Diff(string(current), string(content)); diff != "" {
+			t.Errorf("%s is outdated, run go generate (-current +expected):\n%s", path, diff)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name        string
+		schemas     orderedMap[*schema]
+		expectedErr error
+	}{
+		{
+			name: "integer enum without labels",
+			schemas: orderedMap[*schema]{
+				{Name: "Level", Value: &schema{Type: "integer", Enum: rawValues("1", "2"), Description: "* `1` - One"}},
+			},
+			expectedErr: errUnnamedEnumValue,
+		},
+		{
+			name: "string enum value without letters",
+			schemas: orderedMap[*schema]{
+				{Name: "Size", Value: &schema{Type: "string", Enum: rawValues(`"small"`, `"2xl"`)}},
+			},
+			expectedErr: errUnnamedEnumValue,
+		},
+		{
+			name: "unknown reference",
+			schemas: orderedMap[*schema]{
+				{Name: "Probe", Value: &schema{Type: "object", Properties: orderedMap[*schema]{
+					{Name: "kind", Value: &schema{Ref: "#/components/schemas/Kind"}},
+				}}},
+			},
+			expectedErr: errUnknownRef,
+		},
+		{
+			name: "inline object",
+			schemas: orderedMap[*schema]{
+				{Name: "Probe", Value: &schema{Type: "object", Properties: orderedMap[*schema]{
+					{Name: "options", Value: &schema{Type: "object", Properties: orderedMap[*schema]{
+						{Name: "a", Value: &schema{Type: "string"}},
+					}}},
+				}}},
+			},
+			expectedErr: errUnsupportedSchema,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var doc document
+
+			doc.Components.Schemas = tc.schemas
+
+			if _, err := generate(&doc, new(overrides)); !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("Expected error %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"agent":        "Agent",
+		"agent_fqdn":   "AgentFQDN",
+		"container_id": "ContainerID",
+		"is_connected": "IsConnected",
+		"awsprobe":     "Awsprobe",
+		"On demand":    "OnDemand",
+		"icmp-echo":    "IcmpEcho",
+		"SNMP status":  "SNMPStatus",
+	}
+
+	for name, expected := range cases {
+		if got := exportedName(name); got != expected {
+			t.Errorf("exportedName(%q) = %q, want %q", name, got, expected)
+		}
+	}
+}
+
+func rawValues(values ...string) []json.RawMessage {
+	raw := make([]json.RawMessage, len(values))
+	for i, v := range values {
+		raw[i] = json.RawMessage(v)
+	}
+
+	return raw
+}
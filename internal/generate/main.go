@@ -0,0 +1,90 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command generate generates the resource constants, the enums, the filter metadata and the models
+// of the client from the OpenAPI schema of the Bleemeo API.
+//
+// The Go names are derived from the schema: the resource constants from their path,
+// the types from the schema names, the fields from the property names, and the enum constants
+// from the labels listed in the description of the enums, or from their values.
+// The names which can't be derived, or which must be kept for compatibility,
+// are pinned in an overrides file.
+//
+// It is run with go generate from the root of the module:
+//
+//	go generate ./...
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	schemaPath := flag.String("schema", "api/openapi.json", "path of the OpenAPI schema")
+	overridesPath := flag.String("overrides", "", "path of the file pinning Go names, if any")
+	dir := flag.String("dir", ".", "root directory of the module, where files are generated")
+
+	flag.Parse()
+
+	if err := run(*schemaPath, *overridesPath, *dir); err != nil {
+		log.Fatalln("Failed to generate code:", err)
+	}
+}
+
+func run(schemaPath, overridesPath, dir string) error {
+	doc, err := loadDocument(schemaPath)
+	if err != nil {
+		return err
+	}
+
+	ovr, err := loadOverrides(overridesPath)
+	if err != nil {
+		return err
+	}
+
+	files, err := generate(doc, ovr)
+	if err != nil {
+		return err
+	}
+
+	for path, content := range files {
+		if err = os.WriteFile(filepath.Join(dir, path), content, 0o644); err != nil { //nolint:gosec
+			return fmt.Errorf("failed to write generated file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func loadDocument(path string) (*document, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	var doc document
+
+	if err = json.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse schema %s: %w", path, err)
+	}
+
+	return &doc, nil
+}
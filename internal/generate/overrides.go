@@ -0,0 +1,57 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// overrides pins the Go names which can't be derived from the schema,
+// mostly to keep the names the client exposed before its code was generated.
+type overrides struct {
+	// The names of the Resource constants, by path.
+	Resources map[string]string `json:"resources"`
+	// The names of the types, by schema name.
+	Types map[string]string `json:"types"`
+	// The names of the struct fields, by schema name and property name joined with a dot.
+	Fields map[string]string `json:"fields"`
+	// The names of the enum constants, without the type prefix, by schema name and value.
+	EnumValues map[string]map[string]string `json:"enum_values"`
+}
+
+// loadOverrides reads the overrides file at the given path.
+// An empty path means that no name is overridden.
+func loadOverrides(path string) (*overrides, error) {
+	var ovr overrides
+
+	if path == "" {
+		return &ovr, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overrides: %w", err)
+	}
+
+	if err = json.Unmarshal(content, &ovr); err != nil {
+		return nil, fmt.Errorf("failed to parse overrides %s: %w", path, err)
+	}
+
+	return &ovr, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A document is the subset of an OpenAPI 3 document used by the generator.
+type document struct {
+	Paths      orderedMap[*pathItem] `json:"paths"`
+	Components struct {
+		Schemas orderedMap[*schema] `json:"schemas"`
+	} `json:"components"`
+}
+
+type pathItem struct {
+	Get *operation `json:"get"`
+}
+
+type operation struct {
+	Parameters []parameter          `json:"parameters"`
+	Responses  map[string]*response `json:"responses"`
+}
+
+type parameter struct {
+	Name string `json:"name"`
+	In   string `json:"in"`
+}
+
+type response struct {
+	Content map[string]struct {
+		Schema *schema `json:"schema"`
+	} `json:"content"`
+}
+
+type schema struct {
+	Ref         string              `json:"$ref"` //nolint:tagliatelle
+	Description string              `json:"description"`
+	Type        string              `json:"type"`
+	Format      string              `json:"format"`
+	Nullable    bool                `json:"nullable"`
+	ReadOnly    bool                `json:"readOnly"` //nolint:tagliatelle
+	Enum        []json.RawMessage   `json:"enum"`
+	Items       *schema             `json:"items"`
+	AllOf       []*schema           `json:"allOf"` //nolint:tagliatelle
+	Properties  orderedMap[*schema] `json:"properties"`
+	// The schema of the values of a map.
+	AdditionalProperties *schema `json:"additionalProperties"` //nolint:tagliatelle
+}
+
+// An orderedMap is a JSON object whose keys are kept in the order of the document,
+// so that the generated code follows the order of the schema.
+type orderedMap[V any] []entry[V]
+
+type entry[V any] struct {
+	Name  string
+	Value V
+}
+
+var errUnexpectedJSONToken = errors.New("unexpected JSON token")
+
+func (m *orderedMap[V]) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if tok != json.Delim('{') {
+		return fmt.Errorf("%w %v, expected an object", errUnexpectedJSONToken, tok)
+	}
+
+	for dec.More() {
+		tok, err = dec.Token()
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		var value V
+
+		if err = dec.Decode(&value); err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		*m = append(*m, entry[V]{Name: tok.(string), Value: value}) //nolint:forcetypeassert
+	}
+
+	_, err = dec.Token()
+
+	return err //nolint:wrapcheck
+}
+
+// get returns the value associated with the given name, if any.
+func (m orderedMap[V]) get(name string) (V, bool) {
+	for _, e := range m {
+		if e.Name == name {
+			return e.Value, true
+		}
+	}
+
+	var zero V
+
+	return zero, false
+}
+
+// refName returns the name of the schema targeted by the given reference.
+func refName(ref string) string {
+	return strings.TrimPrefix(ref, "#/components/schemas/")
+}
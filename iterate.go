@@ -0,0 +1,84 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"net/url"
+)
+
+// Iterate returns a sequence over all the resources of the given kind matching the given params,
+// each one being decoded into a T. The given options customize the underlying [Iterator].
+// If an error occurs during the iteration or while decoding a resource,
+// it is yielded along with the zero value of T, and the sequence stops.
+func Iterate[T any](
+	ctx context.Context, c *Client, resource Resource, params url.Values, opts ...IteratorOption,
+) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		iterator := c.Iterator(resource, params, opts...)
+		defer iterator.Close()
+
+		for iterator.Next(ctx) {
+			var item T
+
+			if err := json.Unmarshal(iterator.At(), &item); err != nil {
+				yield(zero, &JSONUnmarshalError{
+					jsonError: &jsonError{
+						Err:      err,
+						DataKind: JsonErrorDataKind_Resource,
+						Data:     iterator.At(),
+					},
+				})
+
+				return
+			}
+
+			if !yield(item, nil) {
+				return
+			}
+		}
+
+		if err := iterator.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// Collect gathers the items of the given sequence into a slice.
+// If maxItems is positive, at most maxItems items are gathered, otherwise all of them are.
+// It stops at the first error, which is returned along with the items gathered until then.
+func Collect[T any](seq iter.Seq2[T, error], maxItems int) ([]T, error) {
+	var items []T
+
+	for item, err := range seq {
+		if err != nil {
+			return items, err
+		}
+
+		items = append(items, item)
+
+		if maxItems > 0 && len(items) >= maxItems {
+			break
+		}
+	}
+
+	return items, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type metricID struct {
+	ID int `json:"id"`
+}
+
+func TestIterate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("all items", func(t *testing.T) {
+		t.Parallel()
+
+		client, requestCounter := makeClientMockForIteration(t, makeMetricMockHandler(t, 6))
+
+		params := url.Values{"page_size": {"2"}}
+
+		metrics, err := Collect(Iterate[metricID](context.Background(), client, ResourceMetric, params), 0)
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+
+		expectedMetrics := []metricID{{1}, {2}, {3}, {4}, {5}, {6}}
+		if diff := cmp.Diff(expectedMetrics, metrics); diff != "" {
+			t.Fatalf("Unexpected metrics (-want +got):\n%s", diff)
+		}
+
+		if requestCounter["/v1/metric/"] != 3 {
+			t.Fatalf("Expected 3 pages to be fetched, got %d", requestCounter["/v1/metric/"])
+		}
+	})
+
+	t.Run("max items", func(t *testing.T) {
+		t.Parallel()
+
+		client, requestCounter := makeClientMockForIteration(t, makeMetricMockHandler(t, 6))
+
+		params := url.Values{"page_size": {"2"}}
+
+		metrics, err := Collect(Iterate[metricID](context.Background(), client, ResourceMetric, params), 3)
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+
+		if diff := cmp.Diff([]metricID{{1}, {2}, {3}}, metrics); diff != "" {
+			t.Fatalf("Unexpected metrics (-want +got):\n%s", diff)
+		}
+
+		if requestCounter["/v1/metric/"] != 2 {
+			t.Fatalf("Expected 2 pages to be fetched, got %d", requestCounter["/v1/metric/"])
+		}
+	})
+
+	t.Run("decoding error", func(t *testing.T) {
+		t.Parallel()
+
+		handler := func(*http.Request) (int, []byte, error) {
+			return http.StatusOK, []byte(`{"results": [{"id": 1}, {"id": "two"}, {"id": 3}]}`), nil
+		}
+
+		client, _ := makeClientMockForIteration(t, handler)
+
+		metrics, err := Collect(Iterate[metricID](context.Background(), client, ResourceMetric, nil), 0)
+		if unmarshalErr := new(JSONUnmarshalError); !errors.As(err, &unmarshalErr) {
+			t.Fatalf("Expected a JSONUnmarshalError, got %v", err)
+		}
+
+		if diff := cmp.Diff([]metricID{{1}}, metrics); diff != "" {
+			t.Fatalf("Unexpected metrics (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("iteration error", func(t *testing.T) {
+		t.Parallel()
+
+		handler := func(*http.Request) (int, []byte, error) {
+			return http.StatusInternalServerError, nil, nil
+		}
+
+		client, _ := makeClientMockForIteration(t, handler)
+
+		errorsCount := 0
+
+		for _, err := range Iterate[metricID](context.Background(), client, ResourceMetric, nil) {
+			errorsCount++
+
+			if apiErr := new(APIError); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+				t.Fatalf("Expected an APIError with status 500, got %v", err)
+			}
+		}
+
+		if errorsCount != 1 {
+			t.Fatalf("Expected a single error to be yielded, got %d", errorsCount)
+		}
+	})
+}
@@ -19,20 +19,33 @@ package bleemeo
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"iter"
 	"net/http"
 	"net/url"
+	"runtime"
 )
 
-const defaultIteratorPageSize = "2500"
+const (
+	defaultIteratorPageSize = "2500"
+	// The maximum number of bytes of a result page kept to be reported if the page turns out to be invalid.
+	pageErrorDataMaxLength = 64 << 10 // 64KB
+)
 
 // An Iterator allows browsing all the resources of a specific kind,
 // optionally matching specified parameters,
 // and automatically fetching the next page when needed.
+// Pages are decoded while being received, so only the current resource is held in memory.
+// The page being read is kept open between calls to Next(), until it has been read entirely,
+// or Close() is called. Pages are fetched with a context owned by the iterator,
+// so the context given to Next() only needs to last for that call.
 type Iterator interface {
 	// Next sets the iteration cursor on the next resource,
 	// fetching the next result page if necessary.
 	// It returns whether iteration can continue or not.
+	// If the given context is done while Next is waiting for the API, the iteration is stopped.
 	Next(ctx context.Context) bool
 	// At returns the resource reached with the last call to Next().
 	// If Next() hasn't been called or returned false, At() mustn't be called.
@@ -42,9 +55,34 @@ type Iterator interface {
 	// All returns an iterator over all the selected resources.
 	// Note that the iterator will stop if any error is encountered.
 	All(ctx context.Context) iter.Seq[json.RawMessage]
+	// Close releases the page being read, if any.
+	// It must be called when the iteration is stopped before Next() returns false,
+	// otherwise the connection the page is being received on is kept open
+	// until the iterator is garbage collected. Once closed, Next() always returns false.
+	Close()
+	// Checkpoint returns the position of the iterator, right after the last resource returned by Next().
+	// An iterator resuming from this position can be created with the WithCheckpoint() option.
+	// When pages are fetched in parallel, the zero Checkpoint is returned.
+	Checkpoint() Checkpoint
+}
+
+// An IteratorOption customizes the behavior of an [Iterator].
+type IteratorOption func(iter *iterator)
+
+// WithParallelFetch makes the iterator fetch up to concurrency pages at the same time.
+// The total count of resources, returned with the first page, is used to plan the page numbers
+// to fetch with Client.GetPage(). If preserveOrder is false, the resources of each page are returned
+// as soon as it has been received, regardless of its position in the listing.
+// The iteration stops at the first error. Pages are decoded entirely before being returned,
+// so up to concurrency pages may be held in memory.
+func WithParallelFetch(concurrency int, preserveOrder bool) IteratorOption {
+	return func(iter *iterator) {
+		iter.concurrency = max(concurrency, 1)
+		iter.preserveOrder = preserveOrder
+	}
 }
 
-func newIterator(c *Client, resource Resource, params url.Values) *iterator {
+func newIterator(c *Client, resource Resource, params url.Values, opts ...IteratorOption) *iterator {
 	if !params.Has("page_size") {
 		if params == nil {
 			params = url.Values{"page_size": {defaultIteratorPageSize}}
@@ -53,11 +91,33 @@ func newIterator(c *Client, resource Resource, params url.Values) *iterator {
 		}
 	}
 
-	return &iterator{
+	iter := &iterator{
 		c:        c,
 		resource: resource,
 		params:   cloneMap(params),
 	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(iter)
+		}
+	}
+
+	if iter.consistency != nil && iter.pageURL != "" {
+		// The resources returned before the checkpoint are unknown.
+		iter.consistency.resumed = true
+	}
+
+	if iter.pageURL == "" {
+		firstPageURL, err := url.JoinPath("/", resource)
+		if err != nil {
+			iter.err = err
+		}
+
+		iter.pageURL = firstPageURL + "?" + iter.params.Encode()
+	}
+
+	return iter
 }
 
 type iterator struct {
@@ -65,35 +125,174 @@ type iterator struct {
 	resource Resource
 	params   url.Values
 
-	currentPage  *ResultsPage
-	currentIndex int
-	err          error
+	// Whether the first page has been requested.
+	started bool
+	// The URL of the current page, and the number of its resources that have been read.
+	pageURL string
+	index   int
+	// The number of resources to skip, when resuming from a checkpoint.
+	skip int
+	// The URL of the next page, empty once the last page has been reached.
+	nextURL string
+	page    *pageDecoder
+	current json.RawMessage
+	err     error
+
+	// When fetching pages in parallel, their results are buffered.
+	concurrency   int
+	preserveOrder bool
+	parallel      *parallelFetcher
+	results       []json.RawMessage
+
+	prefetchEnabled bool
+	prefetch        *pagePrefetch
+
+	consistency *consistencyChecker
+	// Whether the iteration has been stopped by Close().
+	closed bool
+
+	// The context pages are fetched with, created by the first call to Next().
+	// It's canceled when the iterator is released, or when the context given
+	// to Next() is done while the call is running.
+	ctx    context.Context //nolint:containedctx
+	cancel context.CancelCauseFunc
 }
 
 func (iter *iterator) Next(ctx context.Context) bool {
+	if iter.ctx == nil {
+		iter.ctx, iter.cancel = context.WithCancelCause(context.WithoutCancel(ctx))
+
+		// An iterator abandoned without being closed releases its page once garbage collected.
+		runtime.AddCleanup(iter, func(cancel context.CancelCauseFunc) { cancel(nil) }, iter.cancel)
+	}
+
+	// The given context only interrupts the iteration while this call is running.
+	stop := context.AfterFunc(ctx, func() { iter.cancel(ctx.Err()) })
+	defer stop()
+
+	for iter.next(ctx) {
+		if iter.consistency == nil || iter.consistency.accept(iter.current) {
+			return true
+		}
+	}
+
+	if iter.consistency != nil && iter.err == nil && !iter.closed {
+		iter.err = iter.consistency.check(iter.resource)
+		iter.consistency = nil // Only report the inconsistency once
+	}
+
+	return false
+}
+
+// next moves to the next resource of the listing.
+func (iter *iterator) next(ctx context.Context) bool {
+	iter.current = nil
+
 	if iter.err != nil {
 		return false
 	}
 
-	if iter.currentPage == nil || iter.currentIndex >= len(iter.currentPage.Results)-1 {
-		if !iter.fetchPage(ctx) {
+	if err := ctx.Err(); err != nil {
+		iter.err = err
+
+		return false
+	}
+
+	if iter.concurrency > 0 {
+		return iter.nextParallel(ctx)
+	}
+
+	for {
+		if iter.page == nil && !iter.fetchPage(ctx) {
 			return false
 		}
 
-		iter.currentIndex = 0
-	} else {
-		iter.currentIndex++
+		result, ok, err := iter.page.nextResult()
+		if err != nil {
+			page := iter.page
+
+			iter.closePage()
+			iter.cancelPrefetch()
+			iter.setDecodingError(ctx, page, err)
+
+			return false
+		}
+
+		if ok {
+			iter.index++
+
+			if iter.skip > 0 {
+				iter.skip--
+
+				continue
+			}
+
+			iter.current = result
+
+			return true
+		}
+
+		iter.nextURL = iter.page.next
+		iter.observeCount(iter.page.count)
+		iter.closePage()
+	}
+}
+
+// observeCount records the count of resources announced by the API with a page.
+func (iter *iterator) observeCount(count int) {
+	if iter.consistency != nil {
+		iter.consistency.observeCount(count)
+	}
+}
+
+// nextParallel moves to the next resource, when pages are fetched in parallel.
+func (iter *iterator) nextParallel(ctx context.Context) bool {
+	for len(iter.results) == 0 {
+		var (
+			ok  bool
+			err error
+		)
+
+		switch {
+		case iter.parallel != nil:
+			iter.results, ok, err = iter.parallel.next()
+		case !iter.started:
+			iter.started = true
+			iter.parallel, iter.results, err = newParallelFetcher(
+				iter.ctx, iter.c, iter.resource, iter.params, iter.concurrency, iter.preserveOrder,
+			)
+			ok = err == nil
+
+			if ok {
+				iter.observeCount(iter.parallel.count)
+			}
+		}
+
+		if err != nil {
+			iter.err = iter.interruption(ctx, err)
+			iter.release()
+
+			return false
+		}
+
+		if !ok {
+			iter.release()
+
+			return false
+		}
 	}
 
-	return iter.currentIndex < len(iter.currentPage.Results)
+	iter.current, iter.results = iter.results[0], iter.results[1:]
+
+	return true
 }
 
 func (iter *iterator) At() json.RawMessage {
-	if iter.currentPage == nil || iter.currentIndex >= len(iter.currentPage.Results) {
+	if iter.current == nil {
 		panic("Iterator.At() called in bad conditions")
 	}
 
-	return iter.currentPage.Results[iter.currentIndex]
+	return iter.current
 }
 
 func (iter *iterator) Err() error {
@@ -102,6 +301,8 @@ func (iter *iterator) Err() error {
 
 func (iter *iterator) All(ctx context.Context) iter.Seq[json.RawMessage] {
 	return func(yield func(json.RawMessage) bool) {
+		defer iter.Close()
+
 		for iter.Next(ctx) {
 			if !yield(iter.At()) {
 				return
@@ -114,56 +315,275 @@ func (iter *iterator) All(ctx context.Context) iter.Seq[json.RawMessage] {
 	}
 }
 
-func (iter *iterator) fetchPage(ctx context.Context) (ok bool) {
-	var (
-		reqURI string
-		params url.Values
-		err    error
-	)
-
-	if iter.currentPage == nil { // first fetch
-		reqURI, err = url.JoinPath("/", iter.resource)
-		if err != nil {
-			iter.err = err
+func (iter *iterator) Close() {
+	iter.closed = true
 
-			return false
-		}
+	iter.release()
+}
+
+// release frees the resources held by the iterator, after which no more pages are fetched.
+func (iter *iterator) release() {
+	iter.closePage()
+	iter.cancelPrefetch()
+
+	if iter.parallel != nil {
+		iter.parallel.close()
+
+		iter.parallel = nil
+	}
+
+	if iter.cancel != nil {
+		iter.cancel(nil)
+	}
 
-		params = iter.params
+	iter.started = true
+	iter.nextURL = ""
+	iter.current = nil
+	iter.results = nil
+}
+
+func (iter *iterator) closePage() {
+	if iter.page != nil {
+		iter.page.stopClose()
+		cleanupResponse(iter.page.resp)
+
+		iter.page = nil
+	}
+}
+
+// interruption returns the error of the context given to Next(), or the cause of the cancellation
+// of the iterator's context, if either is done, since the given error has probably been caused by it.
+// Otherwise, err is returned.
+func (iter *iterator) interruption(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	if cause := context.Cause(iter.ctx); cause != nil {
+		return cause
+	}
+
+	return err
+}
+
+// setDecodingError sets the iteration error from an error that occurred while decoding the given page.
+func (iter *iterator) setDecodingError(ctx context.Context, page *pageDecoder, err error) {
+	if ctxErr := iter.interruption(ctx, nil); ctxErr != nil {
+		iter.err = ctxErr
+
+		return
+	}
+
+	iter.err = &JSONUnmarshalError{
+		jsonError: &jsonError{
+			Err:      err,
+			DataKind: JsonErrorDataKind_ResultPage,
+			Data:     page.body.prefix,
+		},
+	}
+}
+
+func (iter *iterator) fetchPage(ctx context.Context) (ok bool) {
+	if !iter.started { // first fetch
+		// The query parameters have been included in the URL of the first page.
+		iter.started = true
 	} else {
-		if iter.currentPage.Next == "" {
+		if iter.nextURL == "" {
 			return false
 		}
 
-		reqURI = iter.currentPage.Next
 		// Query parameters were given back in the next URL,
 		// so no need to re-add them (otherwise, they will grow infinitely).
-		params = nil
+		iter.pageURL = iter.nextURL
+		iter.index = 0
+	}
+
+	reqURI := iter.pageURL
+
+	resp, prefetched, err := iter.takePrefetched(ctx, reqURI)
+	if !prefetched {
+		resp, err = iter.c.doStream(iter.ctx, http.MethodGet, reqURI, nil, true)
 	}
 
-	_, resp, err := iter.c.Do(ctx, http.MethodGet, reqURI, params, true, nil)
 	if err != nil {
-		iter.err = err
+		iter.err = iter.interruption(ctx, err)
 
 		return false
 	}
 
-	var page ResultsPage
+	page := newPageDecoder(iter.ctx, resp)
 
-	err = json.Unmarshal(resp, &page)
-	if err != nil {
-		iter.err = &JSONUnmarshalError{
-			jsonError: &jsonError{
-				Err:      err,
-				DataKind: JsonErrorDataKind_ResultPage,
-				Data:     resp,
-			},
-		}
+	if err = page.readHeader(); err != nil {
+		page.stopClose()
+		cleanupResponse(resp)
+		iter.setDecodingError(ctx, page, err)
 
 		return false
 	}
 
-	iter.currentPage = &page
+	iter.page = page
+	iter.nextURL = ""
+
+	orNoop(iter.c.observer).PageFetched(iter.resource)
+
+	if iter.prefetchEnabled && page.next != "" {
+		iter.startPrefetch(iter.ctx, page.next)
+	}
 
 	return true
 }
+
+var errUnexpectedJSONToken = errors.New("unexpected JSON token")
+
+// pageDecoder decodes a result page while it's being received.
+// The count and next fields are read wherever they appear in the page object.
+type pageDecoder struct {
+	resp *http.Response
+	body *prefixReader
+	dec  *json.Decoder
+	// stopClose prevents the page from being closed once its context is done.
+	stopClose func() bool
+	// Whether the decoder is positioned inside the results array.
+	inResults bool
+
+	count int
+	next  string
+}
+
+// newPageDecoder returns a decoder of the given page, which is closed once the given context is done,
+// so that the connection isn't held by an iteration abandoned without being closed.
+func newPageDecoder(ctx context.Context, resp *http.Response) *pageDecoder {
+	body := &prefixReader{r: resp.Body}
+
+	return &pageDecoder{
+		resp:      resp,
+		body:      body,
+		dec:       json.NewDecoder(body),
+		stopClose: context.AfterFunc(ctx, func() { _ = resp.Body.Close() }),
+	}
+}
+
+// readHeader reads the page until the beginning of its results.
+func (pd *pageDecoder) readHeader() error {
+	if _, err := pd.expectDelim('{'); err != nil {
+		return err
+	}
+
+	return pd.readFields()
+}
+
+// nextResult decodes the next resource of the page, or returns false once all have been read.
+func (pd *pageDecoder) nextResult() (json.RawMessage, bool, error) {
+	if !pd.inResults {
+		return nil, false, nil
+	}
+
+	if pd.dec.More() {
+		var result json.RawMessage
+
+		if err := pd.dec.Decode(&result); err != nil {
+			return nil, false, err //nolint:wrapcheck
+		}
+
+		return result, true, nil
+	}
+
+	if _, err := pd.expectDelim(']'); err != nil {
+		return nil, false, err
+	}
+
+	pd.inResults = false
+
+	// The fields following the results may include the next page URL.
+	return nil, false, pd.readFields()
+}
+
+// readFields reads the fields of the page object
+// until reaching the beginning of the results or the end of the object.
+func (pd *pageDecoder) readFields() error {
+	for pd.dec.More() {
+		tok, err := pd.token()
+		if err != nil {
+			return err
+		}
+
+		switch tok {
+		case "results":
+			isNull, err := pd.expectDelim('[')
+			if err != nil {
+				return err
+			}
+
+			if !isNull {
+				pd.inResults = true
+
+				return nil
+			}
+		case "count":
+			err = pd.dec.Decode(&pd.count)
+		case "next":
+			var next *string
+
+			err = pd.dec.Decode(&next)
+			if next != nil {
+				pd.next = *next
+			}
+		default:
+			var skipped json.RawMessage
+
+			err = pd.dec.Decode(&skipped)
+		}
+
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+
+	_, err := pd.expectDelim('}')
+
+	return err
+}
+
+// expectDelim reads the next token, which must be the given delimiter or null.
+func (pd *pageDecoder) expectDelim(delim json.Delim) (isNull bool, err error) {
+	tok, err := pd.token()
+	if err != nil {
+		return false, err
+	}
+
+	switch tok {
+	case delim:
+		return false, nil
+	case nil:
+		return true, nil
+	default:
+		return false, fmt.Errorf("%w %v, expected %q", errUnexpectedJSONToken, tok, delim)
+	}
+}
+
+// token returns the next JSON token, considering the end of the body as unexpected.
+func (pd *pageDecoder) token() (json.Token, error) {
+	tok, err := pd.dec.Token()
+	if errors.Is(err, io.EOF) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return tok, err //nolint:wrapcheck
+}
+
+// prefixReader keeps a copy of the first bytes read from the underlying reader,
+// so that they can be reported if the page turns out to be invalid.
+type prefixReader struct {
+	r      io.Reader
+	prefix []byte
+}
+
+func (pr *prefixReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+
+	if kept := min(n, pageErrorDataMaxLength-len(pr.prefix)); kept > 0 {
+		pr.prefix = append(pr.prefix, p[:kept]...)
+	}
+
+	return n, err //nolint:wrapcheck
+}
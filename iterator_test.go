@@ -19,11 +19,16 @@ package bleemeo
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"runtime"
 	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -353,4 +358,172 @@ func TestIterator(t *testing.T) { //nolint: maintidx
 			t.Fatalf("Unexpected requests (-want +got):\n%s", diff)
 		}
 	})
+	t.Run("next URL after results", func(t *testing.T) {
+		t.Parallel()
+
+		handler := func(r *http.Request) (statusCode int, body []byte, err error) {
+			if r.URL.Query().Get("page") == "2" {
+				return http.StatusOK, []byte(`{"results": null, "count": 3, "extra": {"a": [1, 2]}}`), nil
+			}
+
+			return http.StatusOK, []byte(
+				`{"results": [{"id": 1}, {"id": 2}, {"id": 3}], "count": 3, "next": "/v1/metric/?page=2"}`,
+			), nil
+		}
+
+		client, requestCounter := makeClientMockForIteration(t, handler)
+		iter := client.Iterator(ResourceMetric, url.Values{})
+
+		var ids []string
+
+		for iter.Next(context.Background()) {
+			ids = append(ids, string(iter.At()))
+		}
+
+		if err := iter.Err(); err != nil {
+			t.Fatal("Iterator error:", err)
+		}
+
+		if diff := cmp.Diff([]string{`{"id": 1}`, `{"id": 2}`, `{"id": 3}`}, ids); diff != "" {
+			t.Fatalf("Unexpected objects (-want +got):\n%s", diff)
+		}
+
+		if requestCounter["/v1/metric/"] != 2 {
+			t.Fatalf("Expected 2 pages to be fetched, got %d", requestCounter["/v1/metric/"])
+		}
+	})
+
+	t.Run("early close", func(t *testing.T) {
+		t.Parallel()
+
+		client, requestCounter := makeClientMockForIteration(t, makeMetricMockHandler(t, 15))
+		iter := client.Iterator(ResourceMetric, url.Values{"page_size": {"5"}})
+
+		for range iter.All(context.Background()) {
+			break
+		}
+
+		if iter.Next(context.Background()) {
+			t.Fatal("Expected Next() to return false once the iterator is closed")
+		}
+
+		if requestCounter["/v1/metric/"] != 1 {
+			t.Fatalf("Expected a single page to be fetched, got %d", requestCounter["/v1/metric/"])
+		}
+	})
+
+	t.Run("short-lived contexts", func(t *testing.T) {
+		t.Parallel()
+
+		// The pages are large enough not to be decoded from a single read.
+		client, _ := makeClientMockForIteration(t, makeMetricMockHandler(t, 200))
+		iter := client.Iterator(ResourceMetric, url.Values{"page_size": {"100"}})
+
+		defer iter.Close()
+
+		count := 0
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			ok := iter.Next(ctx)
+
+			cancel()
+
+			if !ok {
+				break
+			}
+
+			count++
+
+			// Processing the resource leaves time for anything reacting to the cancellation.
+			time.Sleep(time.Millisecond)
+		}
+
+		if err := iter.Err(); err != nil {
+			t.Fatal("Iterator error:", err)
+		}
+
+		if count != 200 {
+			t.Fatalf("Expected 200 resources, got %d", count)
+		}
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := makeClientMockForIteration(t, makeMetricMockHandler(t, 15))
+		iter := client.Iterator(ResourceMetric, url.Values{"page_size": {"5"}})
+
+		defer iter.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if iter.Next(ctx) {
+			t.Fatal("Expected Next() to return false once its context is done")
+		}
+
+		if err := iter.Err(); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected the context error, got %v", err)
+		}
+	})
+
+	t.Run("abandoned iteration", func(t *testing.T) {
+		t.Parallel()
+
+		closed := make(chan struct{})
+		trackBodyClose := func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				resp, err := next(req)
+				if err == nil && req.URL.Path == "/v1/metric/" {
+					resp.Body = &closeNotifier{ReadCloser: resp.Body, closed: closed}
+				}
+
+				return resp, err
+			}
+		}
+
+		client, _ := makeClientMockForIteration(t, makeMetricMockHandler(t, 15), WithMiddleware(trackBodyClose))
+
+		// The iteration is abandoned without calling Close()
+		func() {
+			iter := client.Iterator(ResourceMetric, url.Values{"page_size": {"5"}})
+
+			if !iter.Next(context.Background()) {
+				t.Fatal("Expected a first resource, got error:", iter.Err())
+			}
+		}()
+
+		select {
+		case <-closed:
+			t.Fatal("The page has been closed while the iterator was still referenced")
+		default:
+		}
+
+		deadline := time.After(5 * time.Second)
+
+		for {
+			runtime.GC()
+
+			select {
+			case <-closed:
+				return
+			case <-deadline:
+				t.Fatal("The page hasn't been closed once the iterator was garbage collected")
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	})
+}
+
+type closeNotifier struct {
+	io.ReadCloser
+	closed chan struct{}
+	once   sync.Once
+}
+
+func (cn *closeNotifier) Close() error {
+	cn.once.Do(func() { close(cn.closed) })
+
+	return cn.ReadCloser.Close() //nolint:wrapcheck
 }
@@ -0,0 +1,184 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Bodies are truncated to this size in debug records.
+	maxLoggedBodySize = 4 << 10 // 4KB
+	redactedValue     = "REDACTED"
+)
+
+//nolint:gochecknoglobals
+var (
+	discardLogger = slog.New(slog.DiscardHandler)
+	// Headers whose value must never be logged.
+	sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+	// JSON fields whose value must never be logged.
+	sensitiveFields = map[string]bool{
+		"password":      true,
+		"access_token":  true,
+		"refresh_token": true,
+		"client_secret": true,
+		"token":         true,
+	}
+)
+
+// orDiscard returns the given logger, or a logger discarding all records if it is nil.
+func orDiscard(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return discardLogger
+	}
+
+	return logger
+}
+
+// logRequest emits a record about the execution of the given request.
+// If the logger accepts debug records, another record is emitted with the request headers
+// and both the request and response bodies, with any secret redacted.
+func logRequest(
+	ctx context.Context,
+	logger *slog.Logger,
+	req *http.Request,
+	attempt, statusCode int,
+	respBody []byte,
+	duration time.Duration,
+	err error,
+) {
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.Int("status", statusCode),
+		slog.Duration("duration", duration),
+		slog.Int("attempt", attempt),
+	}
+	level := slog.LevelInfo
+
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+		level = slog.LevelWarn
+	}
+
+	logger.LogAttrs(ctx, level, "API request", attrs...)
+
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	var reqBody []byte
+
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = io.ReadAll(io.LimitReader(body, errorRespMaxLength+1))
+			_ = body.Close()
+		}
+	}
+
+	if apiErr := new(APIError); errors.As(err, &apiErr) {
+		respBody = apiErr.Response
+	}
+
+	logger.LogAttrs(ctx, slog.LevelDebug, "API request details",
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.Any("request_headers", redactedHeaders(req.Header)),
+		slog.String("request_body", redactBody(reqBody)),
+		slog.String("response_body", redactBody(respBody)),
+	)
+}
+
+// redactedHeaders is an [http.Header] which hides the value of sensitive headers when logged.
+type redactedHeaders http.Header
+
+func (rh redactedHeaders) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(rh))
+
+	for header, values := range rh {
+		value := strings.Join(values, ", ")
+
+		for _, sensitiveHeader := range sensitiveHeaders {
+			if http.CanonicalHeaderKey(header) == sensitiveHeader {
+				value = redactedValue
+			}
+		}
+
+		attrs = append(attrs, slog.String(header, value))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// redactBody returns the given JSON body with the value of sensitive fields redacted,
+// truncated to maxLoggedBodySize.
+// Bodies which aren't valid JSON, or are too large to be checked, are replaced by their size.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if len(body) > errorRespMaxLength {
+		return "<" + strconv.Itoa(len(body)) + " bytes body omitted>"
+	}
+
+	var content any
+
+	if err := json.Unmarshal(body, &content); err != nil {
+		return "<" + strconv.Itoa(len(body)) + " bytes non-JSON body omitted>"
+	}
+
+	redacted, err := json.Marshal(redactValue(content))
+	if err != nil {
+		return "<" + strconv.Itoa(len(body)) + " bytes body omitted>"
+	}
+
+	if len(redacted) > maxLoggedBodySize {
+		return string(redacted[:maxLoggedBodySize]) + "... (truncated)"
+	}
+
+	return string(redacted)
+}
+
+// redactValue recursively replaces the value of sensitive fields in the given JSON value.
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, fieldValue := range v {
+			if sensitiveFields[strings.ToLower(key)] {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactValue(fieldValue)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+
+	return value
+}
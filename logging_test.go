@@ -0,0 +1,129 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLogging(t *testing.T) {
+	t.Parallel()
+
+	logs := new(bytes.Buffer)
+	logger := slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	requestCounter := make(map[string]int)
+	clientMock := &http.Client{
+		Transport: &transportMock{
+			handlers: map[string]mockHandler{
+				tokenPath: authMockHandler,
+				"/v1/user/": func(*http.Request) (int, []byte, error) {
+					return http.StatusCreated, []byte(`{"id": "1", "email": "user@example.com"}`), nil
+				},
+			},
+			counters: requestCounter,
+		},
+	}
+
+	client, err := NewClient(WithCredentials("u", "user-password"), WithHTTPClient(clientMock), WithLogger(logger))
+	if err != nil {
+		t.Fatal("Failed to initialize client:", err)
+	}
+
+	_, err = client.Create(context.Background(), ResourceUser, map[string]string{
+		"email":    "user@example.com",
+		"password": "new-user-password",
+	})
+	if err != nil {
+		t.Fatal("Failed to create user:", err)
+	}
+
+	var messages []string
+
+	for line := range strings.Lines(logs.String()) {
+		var record struct {
+			Msg string `json:"msg"`
+		}
+
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to unmarshal record %q: %v", line, err)
+		}
+
+		messages = append(messages, record.Msg)
+	}
+
+	expectedMessages := []string{"Fetched a new OAuth token", "API request", "API request details"}
+	if diff := cmp.Diff(expectedMessages, messages); diff != "" {
+		t.Fatalf("Unexpected records (-want +got):\n%s", diff)
+	}
+
+	for _, secret := range []string{"Bearer access", "user-password"} {
+		if strings.Contains(logs.String(), secret) {
+			t.Errorf("Secret %q has been logged:\n%s", secret, logs)
+		}
+	}
+
+	for _, expectedContent := range []string{`"status":201`, `"Authorization":"REDACTED"`, `\"email\":\"user@example.com\"`} {
+		if !strings.Contains(logs.String(), expectedContent) {
+			t.Errorf("Expected logs to contain %s, got:\n%s", expectedContent, logs)
+		}
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "empty",
+			body:     "",
+			expected: "",
+		},
+		{
+			name:     "nested secrets",
+			body:     `{"label": "l", "Password": "p", "items": [{"refresh_token": "r"}], "access_token": {"a": 1}}`,
+			expected: `{"Password":"REDACTED","access_token":"REDACTED","items":[{"refresh_token":"REDACTED"}],"label":"l"}`,
+		},
+		{
+			name:     "not JSON",
+			body:     "password=p",
+			expected: "<10 bytes non-JSON body omitted>",
+		},
+		{
+			name:     "truncated",
+			body:     `"` + strings.Repeat("a", maxLoggedBodySize) + `"`,
+			expected: `"` + strings.Repeat("a", maxLoggedBodySize-1) + "... (truncated)",
+		},
+	}
+
+	for _, tc := range cases {
+		if redacted := redactBody([]byte(tc.body)); redacted != tc.expected {
+			t.Errorf("%s: unexpected redacted body: want %q, got %q", tc.name, tc.expected, redacted)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	authorizePath     = "/o/authorize/"
+	loginCallbackPath = "/callback"
+)
+
+// LoginWithBrowser logs a user in through their browser, using the OAuth authorization code grant with PKCE,
+// and returns the retrieved token, which can be saved in a TokenStore given to WithTokenStore,
+// or whose refresh token can be given to WithInitialOAuthRefreshToken.
+//
+// The authorization code is received by a loopback server listening on a random port of 127.0.0.1,
+// so the OAuth client must allow the redirect URI http://127.0.0.1/callback, regardless of the port.
+// openURL is called with the URL of the authorization page, which the user must visit,
+// e.g. by opening it with the browser or by printing it.
+//
+// The endpoint, the OAuth client, the HTTP client, the middlewares and the logger are taken from the given options.
+// LoginWithBrowser returns once the user has been redirected to the loopback server, or when ctx is done.
+func LoginWithBrowser(
+	ctx context.Context, openURL func(authURL string) error, opts ...ClientOption,
+) (*oauth2.Token, error) {
+	c := configureClient(opts)
+
+	epURL, err := url.Parse(c.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint URL: %w", err)
+	}
+
+	listener, err := new(net.ListenConfig).Listen(ctx, "tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the login server: %w", err)
+	}
+
+	cfg := oauth2.Config{
+		ClientID:     c.oAuthClientID,
+		ClientSecret: c.oAuthClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   epURL.JoinPath(authorizePath).String(),
+			TokenURL:  epURL.JoinPath(tokenPath).String(),
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		RedirectURL: "http://" + listener.Addr().String() + loginCallbackPath,
+	}
+	verifier := oauth2.GenerateVerifier()
+	state := oauth2.GenerateVerifier() // Any unguessable value will do
+	results := make(chan loginResult, 1)
+	server := &http.Server{
+		Handler:           loginCallbackHandler(state, results),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	defer server.Close()
+
+	if err = openURL(cfg.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier))); err != nil {
+		return nil, fmt.Errorf("failed to open the authorization page: %w", err)
+	}
+
+	var result loginResult
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result = <-results:
+		if result.err != nil {
+			return nil, result.err
+		}
+	}
+
+	httpClient := wrapTransportWithUserAgent(wrapTransportWithMiddlewares(c.client, c.middlewares), defaultUserAgent)
+
+	exchangeCtx := context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+
+	tk, err := cfg.Exchange(exchangeCtx, result.code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		if retErr := new(oauth2.RetrieveError); errors.As(err, &retErr) {
+			return nil, buildAuthError(tokenPath, retErr)
+		}
+
+		return nil, fmt.Errorf("failed to exchange the authorization code: %w", err)
+	}
+
+	orDiscard(c.logger).LogAttrs(ctx, slog.LevelInfo, "Fetched a new OAuth token", slog.Time("expiry", tk.Expiry))
+
+	return tk, nil
+}
+
+type loginResult struct {
+	code string
+	err  error
+}
+
+// loginCallbackHandler returns the handler of the loopback server, which sends on results
+// the outcome of the first redirection from the authorization page.
+func loginCallbackHandler(state string, results chan<- loginResult) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(loginCallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("state") != state {
+			// Not a redirection from the authorization page we sent the user to
+			http.Error(w, "Invalid login state", http.StatusBadRequest)
+
+			return
+		}
+
+		var result loginResult
+
+		if errCode := query.Get("error"); errCode != "" {
+			message := query.Get("error_description")
+			if message == "" {
+				message = errCode
+			}
+
+			result.err = &AuthError{
+				APIError: &APIError{
+					ReqPath: authorizePath,
+					Message: message,
+				},
+				ErrorCode: errCode,
+			}
+
+			_, _ = fmt.Fprintln(w, "Login failed:", message)
+		} else {
+			result.code = query.Get("code")
+
+			_, _ = fmt.Fprintln(w, "Login succeeded, you can close this page.")
+		}
+
+		select {
+		case results <- result:
+		default: // Only the first redirection is taken into account
+		}
+	})
+
+	return mux
+}
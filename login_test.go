@@ -0,0 +1,186 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// makeAuthorizationServer returns a fake authorization server, which redirects the user
+// with the given error, or with a code which can be exchanged for a token.
+func makeAuthorizationServer(t *testing.T, clientID, redirectErr string) *httptest.Server {
+	t.Helper()
+
+	const code = "auth-code"
+
+	var challenge, redirectURI string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(authorizePath, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("client_id") != clientID || query.Get("response_type") != "code" ||
+			query.Get("code_challenge_method") != "S256" {
+			t.Errorf("Unexpected authorization request: %s", r.URL.RawQuery)
+		}
+
+		challenge = query.Get("code_challenge")
+		redirectURI = query.Get("redirect_uri")
+
+		if !strings.HasPrefix(redirectURI, "http://127.0.0.1:") {
+			t.Errorf("Unexpected redirect URI %q", redirectURI)
+		}
+
+		params := url.Values{"state": {query.Get("state")}}
+		if redirectErr != "" {
+			params.Set("error", redirectErr)
+		} else {
+			params.Set("code", code)
+		}
+
+		http.Redirect(w, r, redirectURI+"?"+params.Encode(), http.StatusFound)
+	})
+	mux.HandleFunc(tokenPath, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error("Failed to parse token request:", err)
+		}
+
+		verifierHash := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		validChallenge := base64.RawURLEncoding.EncodeToString(verifierHash[:]) == challenge
+
+		if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("code") != code ||
+			r.PostForm.Get("redirect_uri") != redirectURI || !validChallenge {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "invalid_grant", "error_description": "Invalid code or verifier"}`))
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token": "access", "expires_in": 3600, "token_type": "Bearer", "refresh_token": "refresh"}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// visit acts as the browser of the user, following the redirections from the authorization page.
+func visit(authURL string) error {
+	resp, err := http.Get(authURL) //nolint:noctx
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	_, err = io.Copy(io.Discard, resp.Body)
+
+	return err
+}
+
+func TestLoginWithBrowser(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		server := makeAuthorizationServer(t, "cli", "")
+
+		token, err := LoginWithBrowser(t.Context(), visit, WithEndpoint(server.URL), WithOAuthClient("cli", ""))
+		if err != nil {
+			t.Fatal("Failed to login:", err)
+		}
+
+		if token.AccessToken != "access" || token.RefreshToken != "refresh" {
+			t.Fatalf("Unexpected token %+v", token)
+		}
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		t.Parallel()
+
+		server := makeAuthorizationServer(t, defaultOAuthClientID, "access_denied")
+
+		_, err := LoginWithBrowser(t.Context(), visit, WithEndpoint(server.URL))
+		if authErr := new(AuthError); !errors.As(err, &authErr) || authErr.ErrorCode != "access_denied" {
+			t.Fatalf("Expected an access_denied AuthError, got %v", err)
+		}
+	})
+
+	t.Run("invalid state", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+		defer cancel()
+
+		// A forged redirection is rejected, and the login goes on until the context expires.
+		forge := func(authURL string) error {
+			parsedURL, err := url.Parse(authURL)
+			if err != nil {
+				return err
+			}
+
+			redirectURI := parsedURL.Query().Get("redirect_uri")
+
+			resp, err := http.Get(redirectURI + "?code=forged&state=forged") //nolint:noctx
+			if err != nil {
+				return err
+			}
+
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("Expected a forged redirection to be rejected, got status %d", resp.StatusCode)
+			}
+
+			return nil
+		}
+
+		_, err := LoginWithBrowser(ctx, forge)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected error %v, got %v", context.DeadlineExceeded, err)
+		}
+	})
+
+	t.Run("token exchange failure", func(t *testing.T) {
+		t.Parallel()
+
+		server := makeAuthorizationServer(t, defaultOAuthClientID, "")
+
+		// The code challenge is tampered with, so it doesn't match the verifier sent with the token request.
+		tamper := func(authURL string) error {
+			return visit(strings.Replace(authURL, "code_challenge=", "code_challenge=tampered", 1))
+		}
+
+		_, err := LoginWithBrowser(t.Context(), tamper, WithEndpoint(server.URL))
+		if authErr := new(AuthError); !errors.As(err, &authErr) || authErr.ErrorCode != "invalid_grant" {
+			t.Fatalf("Expected an invalid_grant AuthError, got %v", err)
+		}
+	})
+}
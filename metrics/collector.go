@@ -0,0 +1,162 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics exposes the activity of a Bleemeo API client as Prometheus metrics.
+//
+// A Collector must be given to the client with the bleemeo.WithObserver option,
+// and registered in a Prometheus registry:
+//
+//	collector := metrics.NewCollector()
+//	prometheus.MustRegister(collector)
+//
+//	client, err := bleemeo.NewClient(bleemeo.WithObserver(collector), ...)
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/bleemeo/bleemeo-go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "bleemeo_client"
+
+// Collector is a [bleemeo.Observer] which exposes the activity of the clients it observes
+// as Prometheus metrics. It implements [prometheus.Collector].
+// A single Collector can be shared by multiple clients.
+type Collector struct {
+	requests         *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	retries          *prometheus.CounterVec
+	throttles        *prometheus.CounterVec
+	throttleDeadline prometheus.Gauge
+	tokenRetrievals  *prometheus.CounterVec
+	pagesFetched     *prometheus.CounterVec
+}
+
+// NewCollector returns a new Collector, whose metrics are prefixed with "bleemeo_client_".
+func NewCollector() *Collector {
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Number of requests sent to the Bleemeo API, by resource, method and status code.",
+		}, []string{"resource", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the requests sent to the Bleemeo API, by resource and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"resource", "method"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "Number of requests sent again after a transient error or after having been throttled.",
+		}, []string{"resource", "method"}),
+		throttles: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "throttles_total",
+			Help:      "Number of requests throttled by the Bleemeo API, by resource.",
+		}, []string{"resource"}),
+		throttleDeadline: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "throttle_deadline_timestamp_seconds",
+			Help:      "Unix time until which the Bleemeo API throttles the client.",
+		}),
+		tokenRetrievals: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "token_retrievals_total",
+			Help:      "Number of OAuth token retrievals, by kind (fetch or refresh) and result (success or failure).",
+		}, []string{"kind", "result"}),
+		pagesFetched: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "iterator_pages_total",
+			Help:      "Number of result pages fetched by iterators, by resource.",
+		}, []string{"resource"}),
+	}
+}
+
+func (c *Collector) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.requests,
+		c.requestDuration,
+		c.retries,
+		c.throttles,
+		c.throttleDeadline,
+		c.tokenRetrievals,
+		c.pagesFetched,
+	}
+}
+
+// Describe implements [prometheus.Collector].
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range c.collectors() {
+		collector.Describe(ch)
+	}
+}
+
+// Collect implements [prometheus.Collector].
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, collector := range c.collectors() {
+		collector.Collect(ch)
+	}
+}
+
+// RequestDone implements [bleemeo.Observer].
+// Requests which didn't receive any response are counted with the status "error".
+func (c *Collector) RequestDone(
+	resource bleemeo.Resource, method string, statusCode int, duration time.Duration, _ error,
+) {
+	status := "error"
+	if statusCode != 0 {
+		status = strconv.Itoa(statusCode)
+	}
+
+	c.requests.WithLabelValues(resource, method, status).Inc()
+	c.requestDuration.WithLabelValues(resource, method).Observe(duration.Seconds())
+}
+
+// RequestRetried implements [bleemeo.Observer].
+func (c *Collector) RequestRetried(resource bleemeo.Resource, method string) {
+	c.retries.WithLabelValues(resource, method).Inc()
+}
+
+// Throttled implements [bleemeo.Observer].
+func (c *Collector) Throttled(resource bleemeo.Resource, _ time.Duration, deadline time.Time) {
+	c.throttles.WithLabelValues(resource).Inc()
+	c.throttleDeadline.Set(float64(deadline.UnixNano()) / float64(time.Second))
+}
+
+// TokenRetrieved implements [bleemeo.Observer].
+func (c *Collector) TokenRetrieved(refresh bool, err error) {
+	kind, result := "fetch", "success"
+
+	if refresh {
+		kind = "refresh"
+	}
+
+	if err != nil {
+		result = "failure"
+	}
+
+	c.tokenRetrievals.WithLabelValues(kind, result).Inc()
+}
+
+// PageFetched implements [bleemeo.Observer].
+func (c *Collector) PageFetched(resource bleemeo.Resource) {
+	c.pagesFetched.WithLabelValues(resource).Inc()
+}
@@ -0,0 +1,152 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bleemeo/bleemeo-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	t.Parallel()
+
+	agentCalls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/o/token/", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token": "access", "expires_in": 3600, "token_type": "Bearer", "refresh_token": "refresh"}`))
+	})
+	mux.HandleFunc("/v1/agent/1/", func(w http.ResponseWriter, _ *http.Request) {
+		agentCalls++
+		if agentCalls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"id": "1"}`))
+	})
+	mux.HandleFunc("/v1/metric/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			_, _ = w.Write([]byte(`{"count": 2, "results": [{"id": "2"}]}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"count": 2, "next": "/v1/metric/?page=2", "results": [{"id": "1"}]}`))
+	})
+	mux.HandleFunc("/v1/service/", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	collector := NewCollector()
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+
+	client, err := bleemeo.NewClient(
+		bleemeo.WithCredentials("u", "p"),
+		bleemeo.WithEndpoint(server.URL),
+		bleemeo.WithRetryPolicy(bleemeo.RetryPolicy{BaseBackoff: time.Millisecond}),
+		bleemeo.WithObserver(collector),
+	)
+	if err != nil {
+		t.Fatal("Failed to initialize client:", err)
+	}
+
+	ctx := context.Background()
+
+	if _, err = client.Get(ctx, bleemeo.ResourceAgent, "1"); err != nil {
+		t.Fatal("Failed to get agent:", err)
+	}
+
+	iter := client.Iterator(bleemeo.ResourceMetric, url.Values{"page_size": {"1"}})
+	count := 0
+
+	for range iter.All(ctx) {
+		count++
+	}
+
+	if err = iter.Err(); err != nil {
+		t.Fatal("Failed to iterate over metrics:", err)
+	}
+
+	if count != 2 {
+		t.Fatalf("Expected 2 metrics, got %d", count)
+	}
+
+	before := time.Now()
+
+	_, err = client.Get(ctx, bleemeo.ResourceService, "1")
+	if throttleErr := new(bleemeo.ThrottleError); !errors.As(err, &throttleErr) {
+		t.Fatalf("Expected a ThrottleError, got %v", err)
+	}
+
+	expected := `
+# HELP bleemeo_client_iterator_pages_total Number of result pages fetched by iterators, by resource.
+# TYPE bleemeo_client_iterator_pages_total counter
+bleemeo_client_iterator_pages_total{resource="v1/metric/"} 2
+# HELP bleemeo_client_requests_total Number of requests sent to the Bleemeo API, by resource, method and status code.
+# TYPE bleemeo_client_requests_total counter
+bleemeo_client_requests_total{method="GET",resource="v1/agent/",status="200"} 1
+bleemeo_client_requests_total{method="GET",resource="v1/agent/",status="502"} 1
+bleemeo_client_requests_total{method="GET",resource="v1/metric/",status="200"} 2
+bleemeo_client_requests_total{method="GET",resource="v1/service/",status="429"} 1
+# HELP bleemeo_client_retries_total Number of requests sent again after a transient error or after having been throttled.
+# TYPE bleemeo_client_retries_total counter
+bleemeo_client_retries_total{method="GET",resource="v1/agent/"} 1
+# HELP bleemeo_client_throttles_total Number of requests throttled by the Bleemeo API, by resource.
+# TYPE bleemeo_client_throttles_total counter
+bleemeo_client_throttles_total{resource="v1/service/"} 1
+# HELP bleemeo_client_token_retrievals_total Number of OAuth token retrievals, by kind (fetch or refresh) and result (success or failure).
+# TYPE bleemeo_client_token_retrievals_total counter
+bleemeo_client_token_retrievals_total{kind="fetch",result="success"} 1
+`
+
+	err = testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"bleemeo_client_iterator_pages_total",
+		"bleemeo_client_requests_total",
+		"bleemeo_client_retries_total",
+		"bleemeo_client_throttles_total",
+		"bleemeo_client_token_retrievals_total",
+	)
+	if err != nil {
+		t.Fatal("Unexpected metrics:", err)
+	}
+
+	if series := testutil.CollectAndCount(collector, "bleemeo_client_request_duration_seconds"); series != 3 {
+		t.Fatalf("Expected 3 request duration series, got %d", series)
+	}
+
+	deadline := time.Unix(0, int64(testutil.ToFloat64(collector.throttleDeadline)*float64(time.Second)))
+	if minDeadline := before.Add(2 * time.Minute); deadline.Before(minDeadline.Add(-time.Second)) {
+		t.Fatalf("Expected the throttle deadline to be after %s, got %s", minDeadline, deadline)
+	}
+}
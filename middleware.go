@@ -0,0 +1,62 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"net/http"
+)
+
+// A RoundTripFunc executes a single HTTP request and returns its response.
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip implements [http.RoundTripper].
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// A Middleware wraps the execution of requests to add some behavior around it.
+// It receives the next step of the chain, and returns the step that will be called instead.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// chainMiddlewares wraps the given final step with the given middlewares,
+// the first middleware being the outermost one.
+func chainMiddlewares(final RoundTripFunc, middlewares []Middleware) RoundTripFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		final = middlewares[i](final)
+	}
+
+	return final
+}
+
+// wrapTransportWithMiddlewares returns a copy of the given client,
+// whose transport is wrapped with the given middlewares.
+func wrapTransportWithMiddlewares(client *http.Client, middlewares []Middleware) *http.Client {
+	if len(middlewares) == 0 {
+		return client
+	}
+
+	c := *client // Avoid mutating the given client
+
+	initialTransport := client.Transport
+	if initialTransport == nil {
+		initialTransport = http.DefaultTransport
+	}
+
+	c.Transport = chainMiddlewares(initialTransport.RoundTrip, middlewares)
+
+	return &c
+}
@@ -0,0 +1,100 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMiddlewares(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+
+	makeMiddleware := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" "+req.URL.Path+" "+req.Header.Get("User-Agent"))
+				req.Header.Add("X-Middlewares", name)
+
+				resp, err := next(req)
+
+				calls = append(calls, name+" done")
+
+				return resp, err
+			}
+		}
+	}
+
+	var middlewareHeaders []string
+
+	requestCounter := make(map[string]int)
+	clientMock := &http.Client{
+		Transport: &transportMock{
+			handlers: map[string]mockHandler{
+				tokenPath: authMockHandler,
+				"/v1/resource/": func(r *http.Request) (int, []byte, error) {
+					middlewareHeaders = r.Header.Values("X-Middlewares")
+
+					if authHeader := r.Header.Get("Authorization"); authHeader != "Bearer access" {
+						t.Errorf("Unexpected Authorization header %q", authHeader)
+					}
+
+					return http.StatusOK, []byte(`{}`), nil
+				},
+			},
+			counters: requestCounter,
+		},
+	}
+
+	client, err := NewClient(
+		WithCredentials("u", ""),
+		WithHTTPClient(clientMock),
+		WithMiddleware(makeMiddleware("first")),
+		WithMiddleware(makeMiddleware("second")),
+	)
+	if err != nil {
+		t.Fatal("Failed to initialize client:", err)
+	}
+
+	_, _, err = client.Do(context.Background(), http.MethodGet, "/v1/resource/", nil, true, nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	expectedCalls := []string{
+		"first " + tokenPath + " " + defaultUserAgent,
+		"second " + tokenPath + " " + defaultUserAgent,
+		"second done",
+		"first done",
+		"first /v1/resource/ " + defaultUserAgent,
+		"second /v1/resource/ " + defaultUserAgent,
+		"second done",
+		"first done",
+	}
+	if diff := cmp.Diff(expectedCalls, calls); diff != "" {
+		t.Fatalf("Unexpected middleware calls (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{"first", "second"}, middlewareHeaders); diff != "" {
+		t.Fatalf("Unexpected middleware headers (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package models provides typed structs for the core resources of the Bleemeo API,
+// and typed endpoints to get, list, create, update and delete them:
+//
+//	agent, err := models.Agents.Get(ctx, client, agentID)
+//
+//	for metric, err := range models.Metrics.List(ctx, client, params) {
+//	   // ...
+//	}
+//
+// Only the fields held by the models are requested to the API.
+//
+// The fields of the models are omitted from the requests when they are unset, so a model holding
+// only the fields to set can be used as the body of Create and Update. The writable fields
+// whose zero value is meaningful, such as booleans and numbers, are pointers, which Ptr helps to set:
+//
+//	widget, err := models.Widgets.Create(ctx, client, models.Widget{
+//	   DashboardID: dashboard.ID,
+//	   Title:       "CPU",
+//	   Graph:       models.Ptr(bleemeo.Graph_Line),
+//	})
+//
+// The models and their endpoints are generated in models.go from a partial OpenAPI schema of the API,
+// so they may lack fields the API returns, which are ignored when decoding.
+package models
+
+import (
+	"context"
+	"iter"
+	"net/url"
+
+	"github.com/bleemeo/bleemeo-go"
+)
+
+// An Endpoint gives typed access to the resources of a kind, decoded into a T.
+type Endpoint[T any] struct {
+	Resource bleemeo.Resource
+}
+
+// Get retrieves the resource with the given id.
+func (e Endpoint[T]) Get(ctx context.Context, c *bleemeo.Client, id string) (T, error) {
+	return bleemeo.GetInto[T](ctx, c, e.Resource, id)
+}
+
+// List returns a sequence over all the resources matching the given params.
+// The iteration stops at the first error, which is yielded.
+func (e Endpoint[T]) List(
+	ctx context.Context, c *bleemeo.Client, params url.Values, opts ...bleemeo.IteratorOption,
+) iter.Seq2[T, error] {
+	return bleemeo.IterateInto[T](ctx, c, e.Resource, params, opts...)
+}
+
+// Create creates a resource with the given body, and returns the created resource.
+// The body may be a T holding the fields to set, since its unset fields are omitted,
+// or any other value encoded to the JSON representation of the resource.
+func (e Endpoint[T]) Create(ctx context.Context, c *bleemeo.Client, body any) (T, error) {
+	return bleemeo.CreateInto[T](ctx, c, e.Resource, body)
+}
+
+// Update updates the resource with the given id with the given body, and returns the updated resource.
+// Since the request is sent as a PATCH, the body should only hold the fields to update,
+// e.g. as a T where only these fields are set. Use a map[string]any to reset a field to its zero value or to null.
+func (e Endpoint[T]) Update(ctx context.Context, c *bleemeo.Client, id string, body any) (T, error) {
+	return bleemeo.UpdateInto[T](ctx, c, e.Resource, id, body)
+}
+
+// Delete deletes the resource with the given id.
+func (e Endpoint[T]) Delete(ctx context.Context, c *bleemeo.Client, id string) error {
+	return c.Delete(ctx, e.Resource, id) //nolint:wrapcheck
+}
+
+// Ptr returns a pointer to the given value, to set the optional fields of the models.
+func Ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,179 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bleemeo/bleemeo-go"
+	"github.com/google/go-cmp/cmp"
+)
+
+func newTestClient(t *testing.T, mux *http.ServeMux) *bleemeo.Client {
+	t.Helper()
+
+	mux.HandleFunc("/o/token/", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token": "access", "expires_in": 3600, "token_type": "Bearer", "refresh_token": "refresh"}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := bleemeo.NewClient(bleemeo.WithCredentials("u", "p"), bleemeo.WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatal("Failed to initialize client:", err)
+	}
+
+	return client
+}
+
+func TestEndpointGetAndList(t *testing.T) {
+	t.Parallel()
+
+	var requestedFields []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/metric/1/", func(w http.ResponseWriter, r *http.Request) {
+		requestedFields = append(requestedFields, r.URL.Query().Get("fields"))
+
+		_, _ = w.Write([]byte(`{"id": "1", "label": "cpu_used", "current_status": 2, "threshold_high_critical": 90, "deactivated_at": null}`))
+	})
+	mux.HandleFunc("/v1/agent/", func(w http.ResponseWriter, r *http.Request) {
+		requestedFields = append(requestedFields, r.URL.Query().Get("fields"))
+
+		_, _ = w.Write([]byte(`{"count": 2, "results": [
+			{"id": "a1", "fqdn": "host1", "tags": [{"name": "prod", "tag_type": 2}]},
+			{"id": "a2", "fqdn": "host2", "tags": []}
+		]}`))
+	})
+
+	client := newTestClient(t, mux)
+
+	metric, err := Metrics.Get(t.Context(), client, "1")
+	if err != nil {
+		t.Fatal("Failed to get metric:", err)
+	}
+
+	highCritical := 90.
+
+	expectedMetric := Metric{
+		Thresholds:    Thresholds{HighCritical: &highCritical},
+		ID:            "1",
+		Label:         "cpu_used",
+		CurrentStatus: bleemeo.Status_Critical,
+	}
+	if diff := cmp.Diff(expectedMetric, metric); diff != "" {
+		t.Fatalf("Unexpected metric (-want +got):\n%s", diff)
+	}
+
+	var agents []Agent
+
+	for agent, err := range Agents.List(context.Background(), client, url.Values{"active": {"true"}}) {
+		if err != nil {
+			t.Fatal("Failed to list agents:", err)
+		}
+
+		agents = append(agents, agent)
+	}
+
+	expectedAgents := []Agent{
+		{ID: "a1", FQDN: "host1", Tags: []Tag{{Name: "prod", TagType: Ptr(bleemeo.TagType_CreatedByFrontend)}}},
+		{ID: "a2", FQDN: "host2", Tags: []Tag{}},
+	}
+	if diff := cmp.Diff(expectedAgents, agents); diff != "" {
+		t.Fatalf("Unexpected agents (-want +got):\n%s", diff)
+	}
+
+	expectedFields := []string{
+		"threshold_low_warning,threshold_low_critical,threshold_high_warning,threshold_high_critical," +
+			"id,agent,label,item,labels,labels_text,service,container,status_of,current_status,unit,unit_text," +
+			"active,first_seen_at,deactivated_at",
+		"id,account,agent_type,fqdn,display_name,is_cluster_leader,tags,is_connected,created_at,next_config_at," +
+			"current_config",
+	}
+	if diff := cmp.Diff(expectedFields, requestedFields); diff != "" {
+		t.Fatalf("Unexpected requested fields (-want +got):\n%s", diff)
+	}
+}
+
+func TestEndpointCreateAndUpdate(t *testing.T) {
+	t.Parallel()
+
+	var bodies []map[string]any
+
+	handleWidget := func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+
+		content, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(content, &body); err != nil {
+			t.Error("Failed to unmarshal request body:", err)
+		}
+
+		bodies = append(bodies, maps.Clone(body))
+
+		body["id"] = "w1"
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+
+		_ = json.NewEncoder(w).Encode(body)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/widget/", handleWidget)
+	mux.HandleFunc("PATCH /v1/widget/w1/", handleWidget)
+
+	client := newTestClient(t, mux)
+
+	// The zero value of Graph is a valid one, which must be sent.
+	widget, err := Widgets.Create(t.Context(), client, Widget{DashboardID: "d1", Graph: Ptr(bleemeo.Graph_Line)})
+	if err != nil {
+		t.Fatal("Failed to create widget:", err)
+	}
+
+	if diff := cmp.Diff(Widget{ID: "w1", DashboardID: "d1", Graph: Ptr(bleemeo.Graph_Line)}, widget); diff != "" {
+		t.Fatalf("Unexpected created widget (-want +got):\n%s", diff)
+	}
+
+	widget, err = Widgets.Update(t.Context(), client, "w1", Widget{Title: "CPU", Metrics: []string{"cpu_used"}})
+	if err != nil {
+		t.Fatal("Failed to update widget:", err)
+	}
+
+	if diff := cmp.Diff(Widget{ID: "w1", Title: "CPU", Metrics: []string{"cpu_used"}}, widget); diff != "" {
+		t.Fatalf("Unexpected updated widget (-want +got):\n%s", diff)
+	}
+
+	expectedBodies := []map[string]any{
+		{"dashboard": "d1", "graph": float64(bleemeo.Graph_Line)},
+		{"title": "CPU", "metrics": []any{"cpu_used"}},
+	}
+	if diff := cmp.Diff(expectedBodies, bodies); diff != "" {
+		t.Fatalf("Unexpected request bodies (-want +got):\n%s", diff)
+	}
+}
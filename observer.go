@@ -0,0 +1,59 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"time"
+)
+
+// An Observer is notified of the activity of a [Client], e.g. to expose metrics about it.
+// Its methods are called synchronously, so they must return quickly,
+// and must be safe for concurrent use.
+//
+// The metrics subpackage provides an Observer exposing Prometheus metrics.
+type Observer interface {
+	// RequestDone is called after each attempt to execute a request sent through Client.Do().
+	// The status code is zero if no response has been received.
+	RequestDone(resource Resource, method string, statusCode int, duration time.Duration, err error)
+	// RequestRetried is called when a request is about to be sent again,
+	// after a transient error or after having been throttled.
+	RequestRetried(resource Resource, method string)
+	// Throttled is called when the API throttled the client until the given deadline.
+	Throttled(resource Resource, delay time.Duration, deadline time.Time)
+	// TokenRetrieved is called after an attempt to retrieve an OAuth token,
+	// either by fetching a new one or by refreshing the current one.
+	TokenRetrieved(refresh bool, err error)
+	// PageFetched is called when a page of results has been fetched.
+	PageFetched(resource Resource)
+}
+
+type noopObserver struct{}
+
+func (noopObserver) RequestDone(Resource, string, int, time.Duration, error) {}
+func (noopObserver) RequestRetried(Resource, string)                         {}
+func (noopObserver) Throttled(Resource, time.Duration, time.Time)            {}
+func (noopObserver) TokenRetrieved(bool, error)                              {}
+func (noopObserver) PageFetched(Resource)                                    {}
+
+// orNoop returns the given observer, or an observer which does nothing if it is nil.
+func orNoop(observer Observer) Observer {
+	if observer == nil {
+		return noopObserver{}
+	}
+
+	return observer
+}
@@ -0,0 +1,157 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// observerMock records the events it is notified of.
+type observerMock struct {
+	l      sync.Mutex
+	events []string
+}
+
+func (om *observerMock) record(event string) {
+	om.l.Lock()
+	defer om.l.Unlock()
+
+	om.events = append(om.events, event)
+}
+
+func (om *observerMock) RequestDone(resource Resource, method string, statusCode int, _ time.Duration, err error) {
+	om.record(fmt.Sprintf("request %s %s %d (error: %t)", method, resource, statusCode, err != nil))
+}
+
+func (om *observerMock) RequestRetried(resource Resource, method string) {
+	om.record(fmt.Sprintf("retry %s %s", method, resource))
+}
+
+func (om *observerMock) Throttled(resource Resource, delay time.Duration, _ time.Time) {
+	om.record(fmt.Sprintf("throttled %s for %s", resource, delay))
+}
+
+func (om *observerMock) TokenRetrieved(refresh bool, err error) {
+	om.record(fmt.Sprintf("token retrieved (refresh: %t, error: %t)", refresh, err != nil))
+}
+
+func (om *observerMock) PageFetched(resource Resource) {
+	om.record("page " + resource)
+}
+
+func TestObserver(t *testing.T) {
+	t.Parallel()
+
+	t.Run("iteration", func(t *testing.T) {
+		t.Parallel()
+
+		observer := new(observerMock)
+		client, _ := makeClientMockForIteration(t, makeMetricMockHandler(t, 4), WithObserver(observer))
+
+		iter := client.Iterator(ResourceMetric, url.Values{"page_size": {"2"}})
+		count := 0
+
+		for range iter.All(context.Background()) {
+			count++
+		}
+
+		if err := iter.Err(); err != nil {
+			t.Fatal("Unexpected iteration error:", err)
+		}
+
+		if count != 4 {
+			t.Fatalf("Expected 4 resources, got %d", count)
+		}
+
+		expectedEvents := []string{
+			"token retrieved (refresh: false, error: false)",
+			"request GET v1/metric/ 200 (error: false)",
+			"page v1/metric/",
+			"request GET v1/metric/ 200 (error: false)",
+			"page v1/metric/",
+		}
+		if diff := cmp.Diff(expectedEvents, observer.events); diff != "" {
+			t.Fatalf("Unexpected events (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+
+		handler := func(*http.Request) (int, []byte, error) {
+			calls++
+			if calls == 1 {
+				return http.StatusServiceUnavailable, nil, nil
+			}
+
+			return http.StatusOK, []byte(`{}`), nil
+		}
+
+		observer := new(observerMock)
+		client, _ := makeClientMockForDo(t, handler,
+			WithObserver(observer), WithRetryPolicy(RetryPolicy{BaseBackoff: time.Millisecond}))
+
+		_, _, err := client.Do(context.Background(), http.MethodPost, "/v1/resource/", nil, false, nil)
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+
+		expectedEvents := []string{
+			"request POST v1/resource/ 503 (error: true)",
+			"retry POST v1/resource/",
+			"request POST v1/resource/ 200 (error: false)",
+		}
+		if diff := cmp.Diff(expectedEvents, observer.events); diff != "" {
+			t.Fatalf("Unexpected events (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("throttle", func(t *testing.T) {
+		t.Parallel()
+
+		handler := func(*http.Request) (int, []byte, error) {
+			return http.StatusTooManyRequests, nil, nil
+		}
+
+		observer := new(observerMock)
+		client, _ := makeClientMockForDo(t, handler, WithObserver(observer), WithThrottleMaxAutoRetryDelay(0))
+
+		_, _, err := client.Do(context.Background(), http.MethodGet, "/v1/resource/", nil, false, nil)
+		if throttleErr := new(ThrottleError); !errors.As(err, &throttleErr) {
+			t.Fatalf("Expected a ThrottleError, got %v", err)
+		}
+
+		expectedEvents := []string{
+			"throttled v1/resource/ for 30s",
+			"request GET v1/resource/ 429 (error: true)",
+		}
+		if diff := cmp.Diff(expectedEvents, observer.events); diff != "" {
+			t.Fatalf("Unexpected events (-want +got):\n%s", diff)
+		}
+	})
+}
@@ -17,10 +17,13 @@
 package bleemeo
 
 import (
+	"log/slog"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
 )
 
@@ -67,6 +70,34 @@ func WithInitialOAuthRefreshToken(refreshToken string) ClientOption {
 	}
 }
 
+// WithClientCredentialsGrant will make the client authenticate as the OAuth application
+// given with WithOAuthClient, using the client_credentials grant, instead of as a user.
+// This is meant for automation, and requires a confidential OAuth client with a secret.
+// Since such tokens can't be refreshed, a new token is fetched each time the current one expires.
+func WithClientCredentialsGrant() ClientOption {
+	return func(c *Client) {
+		c.clientCredentialsGrant = true
+	}
+}
+
+// WithTokenSource will make the client authenticate with the tokens returned by the given source,
+// e.g. a secret manager, instead of retrieving them from the API with credentials or a refresh token.
+// The source is responsible for renewing the tokens: the client never refreshes, stores nor revokes them,
+// and a request rejected by the API fails with an AuthError wrapping ErrExternalTokenRejected.
+// Each token is reused until it expires, so the source is only called when a new token is needed;
+// a token without expiry is used as long as the client.
+func WithTokenSource(source oauth2.TokenSource) ClientOption {
+	return func(c *Client) {
+		c.tokenSource = oauth2.ReuseTokenSource(nil, source)
+	}
+}
+
+// WithStaticToken will make the client authenticate with the given access token,
+// as if it had been given with WithTokenSource.
+func WithStaticToken(accessToken string) ClientOption {
+	return WithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken, TokenType: "Bearer"}))
+}
+
 // WithConfigurationFromEnv will make the client retrieve and use configuration options
 // defined in environment variables, such as
 //
@@ -78,7 +109,9 @@ func WithInitialOAuthRefreshToken(refreshToken string) ClientOption {
 //
 // - API URL: "BLEEMEO_API_URL"
 //
-// - Initial refresh token: "BLEEMEO_OAUTH_INITIAL_REFRESH_TOKEN".
+// - Initial refresh token: "BLEEMEO_OAUTH_INITIAL_REFRESH_TOKEN"
+//
+// - Client credentials grant: "BLEEMEO_OAUTH_CLIENT_CREDENTIALS_GRANT", which must be a boolean such as "true".
 func WithConfigurationFromEnv() ClientOption {
 	return func(c *Client) {
 		if username, set := os.LookupEnv("BLEEMEO_USER"); set {
@@ -108,6 +141,10 @@ func WithConfigurationFromEnv() ClientOption {
 		if refreshToken, set := os.LookupEnv("BLEEMEO_OAUTH_INITIAL_REFRESH_TOKEN"); set {
 			c.oAuthInitialRefresh = refreshToken
 		}
+
+		if grant, set := os.LookupEnv("BLEEMEO_OAUTH_CLIENT_CREDENTIALS_GRANT"); set {
+			c.clientCredentialsGrant, _ = strconv.ParseBool(grant)
+		}
 	}
 }
 
@@ -126,9 +163,134 @@ func WithNewOAuthTokenCallback(callback func(token *oauth2.Token)) ClientOption
 	}
 }
 
+// WithTokenStore makes the client load its OAuth token from the given store before fetching a new one,
+// and save each new token to it. The stored token is deleted by Client.Logout().
+// With a store, the client can be created without credentials nor initial refresh token,
+// as long as the store holds a token.
+func WithTokenStore(store TokenStore) ClientOption {
+	return func(c *Client) {
+		c.tokenStore = store
+	}
+}
+
+// WithBackgroundTokenRefresh makes the client renew its OAuth token in the background,
+// the given margin before it expires, so that requests never wait for the token to be renewed.
+// The token is refreshed with its refresh token, or a new one is fetched if the refresh fails
+// and credentials have been provided.
+// Failures are logged and given to the onError callback, if not nil;
+// the token is then renewed by the next request once expired.
+// The background renewal stops when the client logs out or is closed. A client which is no longer used
+// must be closed, otherwise the renewal keeps it from being garbage collected, and keeps retrieving tokens.
+func WithBackgroundTokenRefresh(margin time.Duration, onError func(err error)) ClientOption {
+	return func(c *Client) {
+		c.tokenRefreshMargin = margin
+		c.tokenRefreshErrorCallback = onError
+	}
+}
+
 // WithThrottleMaxAutoRetryDelay defines the delay under which throttled requests are automatically resent.
 func WithThrottleMaxAutoRetryDelay(delay time.Duration) ClientOption {
 	return func(c *Client) {
 		c.throttleMaxAutoRetryDelay = delay
 	}
 }
+
+// WithRetryPolicy makes the client retry requests that failed because of a transient error,
+// such as a 502, 503 or 504 status code or a network error, according to the given policy.
+// By default, only throttled requests are retried.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = &policy
+	}
+}
+
+// WithRateLimit makes the client send at most rps requests per second on average,
+// with bursts of at most burst requests. Requests exceeding the limit wait for their turn.
+// The limit is shared by all the goroutines using the client,
+// and is temporarily lowered when the API throttles the client.
+// If rps isn't greater than zero, requests aren't limited.
+func WithRateLimit(rps float64, burst int) ClientOption {
+	return func(c *Client) {
+		c.rateLimiter = NewRateLimiter(rps, burst)
+	}
+}
+
+// WithRateLimiter makes the client wait for the given limiter before sending each request.
+func WithRateLimiter(limiter RateLimiter) ClientOption {
+	return func(c *Client) {
+		c.rateLimiter = limiter
+	}
+}
+
+// WithThrottleQueue makes the requests sent while the client is throttled wait for the throttle delay to be over,
+// instead of immediately failing with a [ThrottleError], as long as the remaining delay
+// is below the max auto retry delay (see WithThrottleMaxAutoRetryDelay).
+// Waiting requests are then sent one at a time in their arrival order,
+// each one being released once the previous one has started sending its request.
+func WithThrottleQueue() ClientOption {
+	return func(c *Client) {
+		c.queueThrottled = true
+	}
+}
+
+// WithMiddleware adds the given middleware around the execution of every request sent by the client,
+// including the ones retrieving and revoking OAuth tokens.
+// Middlewares are called in the order they are given, the first one seeing the requests first.
+// They are called after the User-Agent and Authorization headers have been set, so they can observe or override them.
+func WithMiddleware(middleware Middleware) ClientOption {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, middleware)
+	}
+}
+
+// WithLogger makes the client emit structured records about the requests it sends
+// and the OAuth tokens it retrieves to the given logger.
+// Request and response bodies are only logged at debug level, truncated, with secrets redacted.
+// By default, nothing is logged.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithTracerProvider makes the client record OpenTelemetry spans with the given provider,
+// for each call to Do and DoRequest and for each OAuth token retrieval.
+// The trace context is propagated to the API through the W3C Trace Context headers.
+func WithTracerProvider(provider trace.TracerProvider) ClientOption {
+	return func(c *Client) {
+		c.tracer = provider.Tracer(tracerName)
+	}
+}
+
+// WithObserver makes the client notify the given observer of its activity:
+// requests, retries, throttling, OAuth token retrievals and fetched pages.
+// The metrics subpackage provides an observer exposing them as Prometheus metrics.
+func WithObserver(observer Observer) ClientOption {
+	return func(c *Client) {
+		c.observer = observer
+	}
+}
+
+// WithResponseCache makes the client store the responses of GET requests in the given cache,
+// and send conditional requests based on their ETag and Last-Modified validators.
+// When the API replies that the resource hasn't changed, the cached body is returned
+// as if it had been sent again with a 200 status code.
+// Creating, updating or deleting a resource invalidates the cached entries of its kind.
+// The cache can be shared by several clients, its entries being scoped to the identity each one authenticates as.
+// NewLRUCache provides an in-memory implementation.
+func WithResponseCache(cache ResponseCache) ClientOption {
+	return func(c *Client) {
+		c.responseCache = cache
+	}
+}
+
+// WithRequestDeduplication makes concurrent identical GET requests sent through Client.Do()
+// share a single execution, instead of being sent to the API multiple times.
+// Requests are identical when they have the same URL, query and account header.
+// Each caller still stops waiting when its own context is canceled,
+// and the shared request is only canceled when all of its callers have given up.
+func WithRequestDeduplication() ClientOption {
+	return func(c *Client) {
+		c.requestGroup = new(requestGroup)
+	}
+}
@@ -218,6 +218,54 @@ func TestOptions(t *testing.T) {
 				epURL:                     defaultEndpointURL,
 			},
 		},
+		{
+			name:    "with retry policy",
+			options: []ClientOption{WithRetryPolicy(RetryPolicy{MaxAttempts: 5, Jitter: 0.2}), creds},
+			expectedClient: &Client{
+				username:                  "u",
+				endpoint:                  defaultEndpoint,
+				oAuthClientID:             defaultOAuthClientID,
+				client:                    oauthMockClient,
+				headers:                   map[string]string{"User-Agent": defaultUserAgent},
+				throttleMaxAutoRetryDelay: defaultThrottleMaxAutoRetryDelay,
+				retryPolicy:               &RetryPolicy{MaxAttempts: 5, Jitter: 0.2},
+				epURL:                     defaultEndpointURL,
+			},
+		},
+		{
+			name:    "with throttle queue",
+			options: []ClientOption{WithThrottleQueue(), creds},
+			expectedClient: &Client{
+				username:                  "u",
+				endpoint:                  defaultEndpoint,
+				oAuthClientID:             defaultOAuthClientID,
+				client:                    oauthMockClient,
+				headers:                   map[string]string{"User-Agent": defaultUserAgent},
+				throttleMaxAutoRetryDelay: defaultThrottleMaxAutoRetryDelay,
+				queueThrottled:            true,
+				epURL:                     defaultEndpointURL,
+			},
+		},
+		{
+			name:    "with client credentials grant from env",
+			env:     map[string]string{"BLEEMEO_OAUTH_CLIENT_CREDENTIALS_GRANT": "true"},
+			options: []ClientOption{WithConfigurationFromEnv(), WithOAuthClient("id", "secret")},
+			expectedClient: &Client{
+				endpoint:                  defaultEndpoint,
+				oAuthClientID:             "id",
+				oAuthClientSecret:         "secret",
+				clientCredentialsGrant:    true,
+				client:                    oauthMockClient,
+				headers:                   map[string]string{"User-Agent": defaultUserAgent},
+				throttleMaxAutoRetryDelay: defaultThrottleMaxAutoRetryDelay,
+				epURL:                     defaultEndpointURL,
+			},
+		},
+		{
+			name:          "with client credentials grant without secret",
+			options:       []ClientOption{WithClientCredentialsGrant()},
+			expectedError: ErrNoOAuthClientSecret,
+		},
 		// We can assume that WithHTTPClient() works since it is used in all the above cases.
 	}
 
@@ -240,7 +288,7 @@ func TestOptions(t *testing.T) {
 
 			cmpOpts := cmp.Options{
 				cmp.AllowUnexported(Client{}),
-				cmpopts.IgnoreFields(Client{}, "authProvider", "l"),
+				cmpopts.IgnoreFields(Client{}, "authProvider", "l", "throttleQueue"),
 				cmp.Comparer(tokenCallbackComparer),
 			}
 			if diff := cmp.Diff(tc.expectedClient, client, cmpOpts); diff != "" {
@@ -0,0 +1,178 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// pageResult is the outcome of fetching a page of results in parallel.
+type pageResult struct {
+	page    int
+	results []json.RawMessage
+	err     error
+}
+
+// parallelFetcher fetches pages of results concurrently, based on their number.
+type parallelFetcher struct {
+	c             *Client
+	resource      Resource
+	params        url.Values
+	pageSize      int
+	preserveOrder bool
+	// The count of resources announced with the first page.
+	count int
+
+	ctx     context.Context //nolint:containedctx
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	results chan pageResult
+	// Each page being fetched or waiting to be consumed holds a token,
+	// which bounds both the concurrency and the number of pages held in memory.
+	tokens chan struct{}
+	// Pages received ahead of their turn, when the order is preserved.
+	pending  map[int]pageResult
+	nextPage int
+}
+
+// newParallelFetcher fetches the first page of results, then starts fetching the following ones,
+// as planned from the total count of resources.
+func newParallelFetcher(
+	ctx context.Context, c *Client, resource Resource, params url.Values, concurrency int, preserveOrder bool,
+) (*parallelFetcher, []json.RawMessage, error) {
+	pageSize, err := strconv.Atoi(params.Get("page_size"))
+	if err != nil || pageSize <= 0 {
+		pageSize, _ = strconv.Atoi(defaultIteratorPageSize)
+	}
+
+	firstPage, err := c.GetPage(ctx, resource, 1, pageSize, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	orNoop(c.observer).PageFetched(resource)
+
+	ctx, cancel := context.WithCancel(ctx)
+	pf := &parallelFetcher{
+		c:             c,
+		resource:      resource,
+		params:        params,
+		pageSize:      pageSize,
+		preserveOrder: preserveOrder,
+		count:         firstPage.Count,
+		ctx:           ctx,
+		cancel:        cancel,
+		results:       make(chan pageResult),
+		tokens:        make(chan struct{}, concurrency),
+		pending:       make(map[int]pageResult),
+		nextPage:      2,
+	}
+
+	pageCount := (firstPage.Count + pageSize - 1) / pageSize
+
+	pf.wg.Add(1)
+
+	go pf.dispatch(pageCount)
+
+	return pf, firstPage.Results, nil
+}
+
+// dispatch starts fetching the pages 2 to pageCount, in order, as soon as a token is available.
+func (pf *parallelFetcher) dispatch(pageCount int) {
+	defer pf.wg.Done()
+
+	var fetchers sync.WaitGroup
+
+	defer func() {
+		fetchers.Wait()
+		close(pf.results)
+	}()
+
+	for page := 2; page <= pageCount; page++ {
+		select {
+		case pf.tokens <- struct{}{}:
+		case <-pf.ctx.Done():
+			return
+		}
+
+		fetchers.Add(1)
+
+		go func() {
+			defer fetchers.Done()
+
+			resultPage, err := pf.c.GetPage(pf.ctx, pf.resource, page, pf.pageSize, pf.params)
+			if err == nil {
+				orNoop(pf.c.observer).PageFetched(pf.resource)
+			}
+
+			select {
+			case pf.results <- pageResult{page: page, results: resultPage.Results, err: err}:
+			case <-pf.ctx.Done():
+			}
+		}()
+	}
+}
+
+// next returns the results of the next page, or false once all the pages have been consumed.
+// The results of the previously returned page must have been consumed.
+func (pf *parallelFetcher) next() ([]json.RawMessage, bool, error) {
+	if pf.nextPage > 2 {
+		<-pf.tokens // The previous page has been consumed
+	}
+
+	for {
+		if result, ok := pf.pending[pf.nextPage]; ok {
+			delete(pf.pending, pf.nextPage)
+			pf.nextPage++
+
+			return result.results, true, nil
+		}
+
+		result, ok := <-pf.results
+		if !ok {
+			if err := pf.ctx.Err(); err != nil {
+				return nil, false, err //nolint:wrapcheck
+			}
+
+			return nil, false, nil
+		}
+
+		if result.err != nil {
+			pf.close()
+
+			return nil, false, result.err
+		}
+
+		if !pf.preserveOrder || result.page == pf.nextPage {
+			pf.nextPage++
+
+			return result.results, true, nil
+		}
+
+		pf.pending[result.page] = result
+	}
+}
+
+// close stops fetching pages, and waits for all the goroutines to return.
+func (pf *parallelFetcher) close() {
+	pf.cancel()
+	pf.wg.Wait()
+}
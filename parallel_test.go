@@ -0,0 +1,185 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func collectIDs(t *testing.T, iter Iterator) []int {
+	t.Helper()
+
+	var ids []int
+
+	for iter.Next(context.Background()) {
+		var obj struct {
+			ID int `json:"id"`
+		}
+
+		if err := json.Unmarshal(iter.At(), &obj); err != nil {
+			t.Fatalf("Failed to unmarshal object %q: %v", iter.At(), err)
+		}
+
+		ids = append(ids, obj.ID)
+	}
+
+	return ids
+}
+
+func TestParallelFetch(t *testing.T) {
+	t.Parallel()
+
+	const (
+		totalResources = 25 // the page size is set to 5
+		concurrency    = 2
+	)
+
+	expectedIDs := make([]int, totalResources)
+	for i := range expectedIDs {
+		expectedIDs[i] = i + 1
+	}
+
+	// slowHandler delays the responses, and records the maximum number of pages fetched at the same time.
+	slowHandler := func(t *testing.T, maxInFlight *int) mockHandler {
+		t.Helper()
+
+		var (
+			l        sync.Mutex
+			inFlight int
+		)
+
+		handler := makeMetricMockHandler(t, totalResources)
+
+		return func(r *http.Request) (int, []byte, error) {
+			l.Lock()
+			inFlight++
+			*maxInFlight = max(*maxInFlight, inFlight)
+			l.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			l.Lock()
+			inFlight--
+			l.Unlock()
+
+			return handler(r)
+		}
+	}
+
+	t.Run("preserved order", func(t *testing.T) {
+		t.Parallel()
+
+		var maxInFlight int
+
+		client, requestCounter := makeClientMockForIteration(t, slowHandler(t, &maxInFlight))
+		iter := client.Iterator(ResourceMetric, url.Values{"page_size": {"5"}}, WithParallelFetch(concurrency, true))
+
+		ids := collectIDs(t, iter)
+		if err := iter.Err(); err != nil {
+			t.Fatal("Iterator error:", err)
+		}
+
+		if diff := cmp.Diff(expectedIDs, ids); diff != "" {
+			t.Fatalf("Unexpected IDs (-want +got):\n%s", diff)
+		}
+
+		if requestCounter["/v1/metric/"] != 5 {
+			t.Fatalf("Expected 5 pages to be fetched, got %d", requestCounter["/v1/metric/"])
+		}
+
+		if maxInFlight > concurrency {
+			t.Fatalf("Expected at most %d pages to be fetched at the same time, got %d", concurrency, maxInFlight)
+		}
+	})
+
+	t.Run("any order", func(t *testing.T) {
+		t.Parallel()
+
+		var maxInFlight int
+
+		client, _ := makeClientMockForIteration(t, slowHandler(t, &maxInFlight))
+		iter := client.Iterator(ResourceMetric, url.Values{"page_size": {"5"}}, WithParallelFetch(concurrency, false))
+
+		ids := collectIDs(t, iter)
+		if err := iter.Err(); err != nil {
+			t.Fatal("Iterator error:", err)
+		}
+
+		slices.Sort(ids)
+
+		if diff := cmp.Diff(expectedIDs, ids); diff != "" {
+			t.Fatalf("Unexpected IDs (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		metricHandler := makeMetricMockHandler(t, totalResources)
+		handler := func(r *http.Request) (int, []byte, error) {
+			if r.URL.Query().Get("page") == "3" {
+				return http.StatusInternalServerError, nil, nil
+			}
+
+			return metricHandler(r)
+		}
+
+		client, _ := makeClientMockForIteration(t, handler)
+		iter := client.Iterator(ResourceMetric, url.Values{"page_size": {"5"}}, WithParallelFetch(concurrency, true))
+
+		ids := collectIDs(t, iter)
+
+		if apiErr := new(APIError); !errors.As(iter.Err(), &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+			t.Fatalf("Expected an APIError with status 500, got %v", iter.Err())
+		}
+
+		if len(ids) > 10 {
+			t.Fatalf("Expected at most the resources of the first two pages, got %v", ids)
+		}
+	})
+
+	t.Run("early stop", func(t *testing.T) {
+		t.Parallel()
+
+		var maxInFlight int
+
+		client, requestCounter := makeClientMockForIteration(t, slowHandler(t, &maxInFlight))
+		iter := client.Iterator(ResourceMetric, url.Values{"page_size": {"5"}}, WithParallelFetch(concurrency, true))
+
+		for range iter.All(context.Background()) {
+			break
+		}
+
+		if iter.Next(context.Background()) {
+			t.Fatal("Expected Next() to return false once the iterator is closed")
+		}
+
+		if requestCounter["/v1/metric/"] > 1+concurrency {
+			t.Fatalf("Expected at most %d pages to be fetched, got %d", 1+concurrency, requestCounter["/v1/metric/"])
+		}
+	})
+}
@@ -0,0 +1,126 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+// WithPrefetch makes the iterator start fetching the next page in the background
+// as soon as the current page is delivered, while its resources are being processed.
+// The prefetched page is entirely received before being used,
+// so up to two pages may be held in memory.
+// The prefetch is canceled when the iterator is closed,
+// or when the context given to Next() is done while the call is running.
+func WithPrefetch() IteratorOption {
+	return func(iter *iterator) {
+		iter.prefetchEnabled = true
+	}
+}
+
+// pagePrefetch is the fetching of a page ahead of its use.
+type pagePrefetch struct {
+	reqURI string
+	cancel context.CancelFunc
+	done   chan struct{}
+	resp   *http.Response
+	err    error
+}
+
+// startPrefetch starts fetching the page with the given URL in the background.
+func (iter *iterator) startPrefetch(ctx context.Context, reqURI string) {
+	ctx, cancel := context.WithCancel(ctx)
+	prefetch := &pagePrefetch{
+		reqURI: reqURI,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(prefetch.done)
+
+		resp, err := iter.c.doStream(ctx, http.MethodGet, reqURI, nil, true)
+		if err != nil {
+			prefetch.err = err
+
+			return
+		}
+
+		body, err := io.ReadAll(resp.Body)
+
+		cleanupResponse(resp)
+
+		if err != nil {
+			prefetch.err = err
+
+			return
+		}
+
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		prefetch.resp = resp
+	}()
+
+	iter.prefetch = prefetch
+}
+
+// takePrefetched returns the response of the page with the given URL if it has been prefetched,
+// waiting for the prefetch to complete if needed.
+// Any other prefetch is canceled.
+func (iter *iterator) takePrefetched(ctx context.Context, reqURI string) (resp *http.Response, ok bool, err error) {
+	prefetch := iter.prefetch
+	if prefetch == nil {
+		return nil, false, nil
+	}
+
+	if prefetch.reqURI != reqURI {
+		iter.cancelPrefetch()
+
+		return nil, false, nil
+	}
+
+	select {
+	case <-prefetch.done:
+	case <-ctx.Done():
+		iter.cancelPrefetch()
+
+		return nil, true, ctx.Err() //nolint:wrapcheck
+	}
+
+	iter.prefetch = nil
+	prefetch.cancel()
+
+	return prefetch.resp, true, prefetch.err
+}
+
+// cancelPrefetch cancels the ongoing prefetch, if any, and waits for it to return.
+func (iter *iterator) cancelPrefetch() {
+	if iter.prefetch == nil {
+		return
+	}
+
+	iter.prefetch.cancel()
+	<-iter.prefetch.done
+
+	if iter.prefetch.resp != nil {
+		cleanupResponse(iter.prefetch.resp)
+	}
+
+	iter.prefetch = nil
+}
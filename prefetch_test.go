@@ -0,0 +1,151 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPrefetch(t *testing.T) {
+	t.Parallel()
+
+	const totalResources = 15 // the page size is set to 5
+
+	t.Run("full iteration", func(t *testing.T) {
+		t.Parallel()
+
+		secondPageFetched := make(chan struct{})
+		metricHandler := makeMetricMockHandler(t, totalResources)
+		handler := func(r *http.Request) (int, []byte, error) {
+			if r.URL.Query().Get("page") == "2" {
+				close(secondPageFetched)
+			}
+
+			return metricHandler(r)
+		}
+
+		client, requestCounter := makeClientMockForIteration(t, handler)
+		iter := client.Iterator(ResourceMetric, url.Values{"page_size": {"5"}}, WithPrefetch())
+
+		if !iter.Next(context.Background()) {
+			t.Fatal("Expected a first resource, got error:", iter.Err())
+		}
+
+		// The second page should be fetched while the first one is being processed.
+		select {
+		case <-secondPageFetched:
+		case <-time.After(time.Second):
+			t.Fatal("The second page hasn't been prefetched")
+		}
+
+		ids := append([]int{1}, collectIDs(t, iter)...)
+		if err := iter.Err(); err != nil {
+			t.Fatal("Iterator error:", err)
+		}
+
+		expectedIDs := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+		if diff := cmp.Diff(expectedIDs, ids); diff != "" {
+			t.Fatalf("Unexpected IDs (-want +got):\n%s", diff)
+		}
+
+		if requestCounter["/v1/metric/"] != 3 {
+			t.Fatalf("Expected 3 pages to be fetched, got %d", requestCounter["/v1/metric/"])
+		}
+	})
+
+	t.Run("short-lived contexts", func(t *testing.T) {
+		t.Parallel()
+
+		metricHandler := makeMetricMockHandler(t, totalResources)
+		handler := func(r *http.Request) (int, []byte, error) {
+			if r.URL.Query().Has("page") {
+				// The prefetch is still running when the call to Next() which started it returns.
+				select {
+				case <-time.After(10 * time.Millisecond):
+				case <-r.Context().Done():
+					return 0, nil, r.Context().Err()
+				}
+			}
+
+			return metricHandler(r)
+		}
+
+		client, _ := makeClientMockForIteration(t, handler)
+		iter := client.Iterator(ResourceMetric, url.Values{"page_size": {"5"}}, WithPrefetch())
+
+		defer iter.Close()
+
+		count := 0
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			ok := iter.Next(ctx)
+
+			cancel()
+
+			if !ok {
+				break
+			}
+
+			count++
+		}
+
+		if err := iter.Err(); err != nil {
+			t.Fatal("Iterator error:", err)
+		}
+
+		if count != totalResources {
+			t.Fatalf("Expected %d resources, got %d", totalResources, count)
+		}
+	})
+
+	t.Run("early stop", func(t *testing.T) {
+		t.Parallel()
+
+		canceled := make(chan struct{})
+		metricHandler := makeMetricMockHandler(t, totalResources)
+		handler := func(r *http.Request) (int, []byte, error) {
+			if r.URL.Query().Get("page") == "2" {
+				<-r.Context().Done()
+				close(canceled)
+
+				return 0, nil, r.Context().Err()
+			}
+
+			return metricHandler(r)
+		}
+
+		client, _ := makeClientMockForIteration(t, handler)
+		iter := client.Iterator(ResourceMetric, url.Values{"page_size": {"5"}}, WithPrefetch())
+
+		for range iter.All(context.Background()) {
+			break
+		}
+
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			t.Fatal("The prefetch hasn't been canceled")
+		}
+	})
+}
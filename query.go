@@ -0,0 +1,176 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A Query builds the parameters of a resource listing,
+// to be given to Client.GetPage(), Client.Count() or Client.Iterator() with its Values() method.
+// Its methods return the query itself, so they can be chained:
+//
+//	query := bleemeo.NewQuery(bleemeo.ResourceMetric).Active(true).Fields("id", "label")
+//	iter := client.Iterator(bleemeo.ResourceMetric, query.Values())
+type Query struct {
+	resource Resource
+	values   url.Values
+	// The fields filtered on, to be validated.
+	filters []string
+}
+
+// NewQuery returns an empty query on the given resource.
+func NewQuery(resource Resource) *Query {
+	return &Query{
+		resource: resource,
+		values:   make(url.Values),
+	}
+}
+
+// Filter restricts the listing to the resources whose field has the given value.
+// The field may include a lookup, e.g. "label__startswith".
+// Booleans are rendered as "True" or "False", as expected by the API.
+func (q *Query) Filter(field string, value any) *Query {
+	q.filters = append(q.filters, field)
+	q.values.Set(field, formatQueryValue(value))
+
+	return q
+}
+
+// In restricts the listing to the resources whose field has one of the given values.
+func (q *Query) In(field string, values ...any) *Query {
+	formatted := make([]string, len(values))
+
+	for i, value := range values {
+		formatted[i] = formatQueryValue(value)
+	}
+
+	q.filters = append(q.filters, field)
+	q.values.Set(field+"__in", strings.Join(formatted, ","))
+
+	return q
+}
+
+// Ordering sorts the listing by the given fields.
+// A field prefixed with "-" sorts in descending order.
+func (q *Query) Ordering(fields ...string) *Query {
+	q.values.Set("ordering", strings.Join(fields, ","))
+
+	return q
+}
+
+// Search restricts the listing to the resources matching the given text.
+func (q *Query) Search(text string) *Query {
+	q.values.Set("search", text)
+
+	return q
+}
+
+// Fields makes the API only return the given fields of each resource.
+// The fewer fields required, the faster the query.
+func (q *Query) Fields(fields ...string) *Query {
+	q.values.Set("fields", strings.Join(fields, ","))
+
+	return q
+}
+
+// Active restricts the listing to the active or inactive resources.
+func (q *Query) Active(active bool) *Query {
+	return q.Filter("active", active)
+}
+
+// PageSize sets the number of resources returned by each page.
+func (q *Query) PageSize(size int) *Query {
+	q.values.Set("page_size", strconv.Itoa(size))
+
+	return q
+}
+
+// Validate checks that all the fields filtered on are known to be filterable for the resource of the query.
+// Resources which have no filterable fields registered aren't checked.
+// The check is best-effort: the fields known by default come from a partial schema of the API,
+// so a filter the API supports may be reported as unknown, unless it's registered with RegisterFilterableFields.
+func (q *Query) Validate() error {
+	known := FilterableFields(q.resource)
+	if known == nil {
+		return nil
+	}
+
+	for _, filter := range q.filters {
+		field, _, _ := strings.Cut(filter, "__")
+		if !slices.Contains(known, field) {
+			return fmt.Errorf("%w %q on %s, expected one of %s",
+				ErrUnknownFilter, field, q.resource, strings.Join(known, ", "))
+		}
+	}
+
+	return nil
+}
+
+// Values returns the parameters built by the query.
+func (q *Query) Values() url.Values {
+	return cloneMap(q.values)
+}
+
+func formatQueryValue(value any) string {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return "True"
+		}
+
+		return "False"
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+//nolint:gochecknoglobals
+var (
+	filtersLock sync.RWMutex
+	// The fields which can be used to filter the listing of each resource.
+	filterableFields = defaultFilterableFields()
+)
+
+// FilterableFields returns the fields known to be filterable for the given resource,
+// or nil if none have been registered. The list may be incomplete, see Query.Validate.
+func FilterableFields(resource Resource) []string {
+	filtersLock.RLock()
+	defer filtersLock.RUnlock()
+
+	return slices.Clone(filterableFields[resource])
+}
+
+// RegisterFilterableFields adds the given fields to the ones known to be filterable for the given resource,
+// e.g. to use filters which have been added to the API after this version of the client.
+func RegisterFilterableFields(resource Resource, fields ...string) {
+	filtersLock.Lock()
+	defer filtersLock.Unlock()
+
+	for _, field := range fields {
+		if !slices.Contains(filterableFields[resource], field) {
+			filterableFields[resource] = append(filterableFields[resource], field)
+		}
+	}
+}
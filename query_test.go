@@ -0,0 +1,95 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	query := NewQuery(ResourceMetric).
+		Filter("label__startswith", "cpu_").
+		In("agent", "a1", "a2").
+		Filter("item", 42).
+		Active(false).
+		Ordering("-label", "item").
+		Search("disk").
+		Fields("id", "label").
+		PageSize(100)
+
+	if err := query.Validate(); err != nil {
+		t.Fatal("Unexpected validation error:", err)
+	}
+
+	expectedValues := url.Values{
+		"label__startswith": {"cpu_"},
+		"agent__in":         {"a1,a2"},
+		"item":              {"42"},
+		"active":            {"False"},
+		"ordering":          {"-label,item"},
+		"search":            {"disk"},
+		"fields":            {"id,label"},
+		"page_size":         {"100"},
+	}
+	if diff := cmp.Diff(expectedValues, query.Values()); diff != "" {
+		t.Fatalf("Unexpected values (-want +got):\n%s", diff)
+	}
+
+	// Mutating the returned values mustn't alter the query.
+	query.Values().Set("search", "other")
+
+	if search := query.Values().Get("search"); search != "disk" {
+		t.Fatalf("Expected the query to be left unchanged, got search=%q", search)
+	}
+}
+
+func TestQueryValidation(t *testing.T) {
+	t.Parallel()
+
+	err := NewQuery(ResourceMetric).Filter("lable", "cpu_used").Validate()
+	if !errors.Is(err, ErrUnknownFilter) {
+		t.Fatalf("Expected error %v, got %v", ErrUnknownFilter, err)
+	}
+
+	err = NewQuery(ResourceMetric).In("agnet", "a1").Validate()
+	if !errors.Is(err, ErrUnknownFilter) {
+		t.Fatalf("Expected error %v, got %v", ErrUnknownFilter, err)
+	}
+
+	// Resources without registered filters aren't checked.
+	if err = NewQuery(ResourceSlo).Filter("anything", 1).Validate(); err != nil {
+		t.Fatal("Unexpected validation error:", err)
+	}
+
+	const resource Resource = "v1/test-query-validation/"
+
+	RegisterFilterableFields(resource, "custom")
+
+	if err = NewQuery(resource).Filter("custom__icontains", "x").Validate(); err != nil {
+		t.Fatal("Unexpected validation error:", err)
+	}
+
+	if err = NewQuery(resource).Filter("other", "x").Validate(); !errors.Is(err, ErrUnknownFilter) {
+		t.Fatalf("Expected error %v, got %v", ErrUnknownFilter, err)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// After being throttled, the rate of the limiter is restored
+	// once this delay has passed without any new throttling.
+	rateLimiterRecoveryDelay = time.Minute
+	// Throttling never lowers the rate of the limiter below its target rate divided by this factor.
+	rateLimiterMinRateDivisor = 8
+)
+
+// A RateLimiter controls the rate at which the client sends requests to the API.
+// It must be safe for concurrent use.
+type RateLimiter interface {
+	// Wait blocks until a request can be sent,
+	// or returns an error if the context is done before.
+	Wait(ctx context.Context) error
+	// Throttled is called when the API answered that no request should be sent
+	// before the given delay, allowing the limiter to adapt its rate.
+	Throttled(delay time.Duration)
+}
+
+// NewRateLimiter returns a token bucket [RateLimiter] which allows on average rps requests per second,
+// with bursts of at most burst requests. If rps isn't greater than zero, no limiter is returned.
+// When the API throttles the client, the limiter holds all requests until the throttle delay is over,
+// then halves its rate, down to an eighth of rps, until no throttling occurred for a minute.
+func NewRateLimiter(rps float64, burst int) RateLimiter {
+	if !(rps > 0) { // Also rejects NaN
+		return nil
+	}
+
+	return &tokenBucketLimiter{
+		rate:       rps,
+		targetRate: rps,
+		burst:      float64(max(burst, 1)),
+		tokens:     float64(max(burst, 1)),
+		lastRefill: time.Now(),
+	}
+}
+
+type tokenBucketLimiter struct {
+	l sync.Mutex
+	// The current rate, which may be lower than the target one after a throttling.
+	rate, targetRate float64
+	burst            float64
+	// The number of available tokens, which becomes negative when requests are waiting.
+	tokens       float64
+	lastRefill   time.Time
+	pausedUntil  time.Time
+	lastThrottle time.Time
+}
+
+func (tbl *tokenBucketLimiter) Wait(ctx context.Context) error {
+	tbl.l.Lock()
+
+	now := time.Now()
+	tbl.refill(now)
+	// Reserving a token, possibly one which will only be available in the future.
+	tbl.tokens--
+
+	// No tokens are earned before the end of the pause.
+	delay := max(tbl.pausedUntil.Sub(now), 0)
+
+	if tbl.tokens < 0 {
+		delay += time.Duration(-tbl.tokens / tbl.rate * float64(time.Second))
+	}
+
+	tbl.l.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Giving back the reserved token
+		tbl.l.Lock()
+		tbl.tokens++
+		tbl.l.Unlock()
+
+		return ctx.Err() //nolint:wrapcheck
+	}
+}
+
+func (tbl *tokenBucketLimiter) Throttled(delay time.Duration) {
+	tbl.l.Lock()
+	defer tbl.l.Unlock()
+
+	now := time.Now()
+	tbl.refill(now)
+
+	if deadline := now.Add(delay); deadline.After(tbl.pausedUntil) {
+		tbl.pausedUntil = deadline
+	}
+
+	tbl.rate = max(tbl.rate/2, tbl.targetRate/rateLimiterMinRateDivisor)
+	tbl.lastThrottle = now
+	// The tokens accumulated during the pause shouldn't be used in a burst.
+	tbl.tokens = min(tbl.tokens, 0)
+}
+
+// refill adds the tokens earned since the last refill to the bucket.
+// It must be called with the lock held.
+func (tbl *tokenBucketLimiter) refill(now time.Time) {
+	if tbl.rate < tbl.targetRate && now.Sub(tbl.lastThrottle) >= rateLimiterRecoveryDelay {
+		tbl.rate = tbl.targetRate
+	}
+
+	// No tokens are earned while requests are paused.
+	from := tbl.lastRefill
+	if tbl.pausedUntil.After(from) {
+		from = tbl.pausedUntil
+	}
+
+	if elapsed := now.Sub(from); elapsed > 0 {
+		tbl.tokens = min(tbl.tokens+elapsed.Seconds()*tbl.rate, tbl.burst)
+	}
+
+	if now.After(tbl.lastRefill) {
+		tbl.lastRefill = now
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type rateLimiterMock struct {
+	l              sync.Mutex
+	waits          int
+	throttleDelays []time.Duration
+	errToReturn    error
+}
+
+func (rlm *rateLimiterMock) Wait(context.Context) error {
+	rlm.l.Lock()
+	defer rlm.l.Unlock()
+
+	rlm.waits++
+
+	return rlm.errToReturn
+}
+
+func (rlm *rateLimiterMock) Throttled(delay time.Duration) {
+	rlm.l.Lock()
+	defer rlm.l.Unlock()
+
+	rlm.throttleDelays = append(rlm.throttleDelays, delay)
+}
+
+func TestTokenBucketLimiter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rate", func(t *testing.T) {
+		t.Parallel()
+
+		const rps, burst, requests = 50, 2, 6
+
+		limiter := NewRateLimiter(rps, burst)
+		start := time.Now()
+
+		var wg sync.WaitGroup
+
+		for range requests {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				if err := limiter.Wait(context.Background()); err != nil {
+					t.Error("Unexpected error:", err)
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		// The burst is immediately allowed, then each request must wait for 1/rps seconds.
+		minElapsed := time.Duration(requests-burst) * time.Second / rps
+		if elapsed := time.Since(start); elapsed < minElapsed-5*time.Millisecond {
+			t.Fatalf("Requests were sent too fast: %s, expected at least %s", elapsed, minElapsed)
+		}
+	})
+
+	t.Run("context cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := NewRateLimiter(0.001, 1)
+
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected error %v, got %v", context.DeadlineExceeded, err)
+		}
+	})
+
+	t.Run("throttled", func(t *testing.T) {
+		t.Parallel()
+
+		const pause = 50 * time.Millisecond
+
+		limiter := NewRateLimiter(1000, 10)
+		limiter.Throttled(pause)
+
+		start := time.Now()
+
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+
+		if elapsed := time.Since(start); elapsed < pause {
+			t.Fatalf("Request was sent during the pause, after %s", elapsed)
+		}
+
+		tbl, _ := limiter.(*tokenBucketLimiter)
+
+		tbl.l.Lock()
+		defer tbl.l.Unlock()
+
+		if tbl.rate != 500 {
+			t.Fatalf("Expected the rate to be halved, got %f", tbl.rate)
+		}
+	})
+
+	t.Run("rate floor", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := NewRateLimiter(800, 10)
+
+		for range 10 {
+			limiter.Throttled(0)
+		}
+
+		tbl, _ := limiter.(*tokenBucketLimiter)
+
+		tbl.l.Lock()
+		defer tbl.l.Unlock()
+
+		if tbl.rate != 100 {
+			t.Fatalf("Expected the rate to stop at an eighth of the target rate, got %f", tbl.rate)
+		}
+	})
+
+	t.Run("invalid rate", func(t *testing.T) {
+		t.Parallel()
+
+		for _, rps := range []float64{0, -1, math.NaN()} {
+			if limiter := NewRateLimiter(rps, 1); limiter != nil {
+				t.Errorf("Expected no limiter for a rate of %f, got %v", rps, limiter)
+			}
+		}
+
+		client, _ := makeClientMockForDo(t, okHandler, WithRateLimit(0, 1))
+		if client.rateLimiter != nil {
+			t.Fatalf("Expected no limiter for a rate of 0, got %v", client.rateLimiter)
+		}
+
+		if _, _, err := client.Do(context.Background(), http.MethodGet, "/v1/resource/", nil, false, nil); err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+	})
+}
+
+func TestClientRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	limiter := &rateLimiterMock{}
+	calls := 0
+	client, _ := makeClientMockForDo(t, func(*http.Request) (int, []byte, error) {
+		calls++
+		if calls == 1 {
+			return http.StatusTooManyRequests, nil, nil
+		}
+
+		return http.StatusOK, []byte(`{}`), nil
+	}, WithRateLimiter(limiter))
+
+	client.client.Transport.(*transportMock).headers = map[string]http.Header{ //nolint:forcetypeassert
+		"/v1/resource/": {"Retry-After": {"0"}},
+	}
+
+	_, _, err := client.Do(context.Background(), http.MethodGet, "/v1/resource/", nil, false, nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	if limiter.waits != 2 {
+		t.Fatalf("Expected the limiter to be waited 2 times, got %d", limiter.waits)
+	}
+
+	if diff := cmp.Diff([]time.Duration{0}, limiter.throttleDelays); diff != "" {
+		t.Fatalf("Unexpected throttle delays (-want +got):\n%s", diff)
+	}
+
+	limiter.errToReturn = context.Canceled
+
+	_, _, err = client.Do(context.Background(), http.MethodGet, "/v1/resource/", nil, false, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected error %v, got %v", context.Canceled, err)
+	}
+}
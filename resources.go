@@ -14,6 +14,8 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// Code generated by internal/generate from the OpenAPI schema. DO NOT EDIT.
+
 package bleemeo
 
 // A Resource represents a route to a model on the Bleemeo API.
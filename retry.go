@@ -0,0 +1,126 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts = 3
+	defaultRetryBaseBackoff = 500 * time.Millisecond
+	defaultRetryMaxBackoff  = 30 * time.Second
+)
+
+// A RetryPolicy describes how requests that failed
+// because of a transient error should be retried.
+// Zero fields are replaced by their default value.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is sent,
+	// including the first attempt. It defaults to 3.
+	MaxAttempts int
+	// BaseBackoff is the delay to wait before the first retry.
+	// It is doubled after each attempt. It defaults to 500ms.
+	BaseBackoff time.Duration
+	// MaxBackoff is the maximum delay to wait between two attempts. It defaults to 30s.
+	MaxBackoff time.Duration
+	// Jitter is the fraction (between 0 and 1) of the backoff which may be randomly subtracted from it,
+	// to avoid multiple clients retrying at the same time.
+	Jitter float64
+	// Retryable returns whether the request that failed with the given error should be retried.
+	// It defaults to IsRetryableError.
+	Retryable func(err error) bool
+}
+
+// IsRetryableError returns whether the given error is considered transient,
+// which is the case for 502, 503 and 504 [APIError] and network errors.
+// Context errors and [ThrottleError] are never considered retryable.
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if throttleErr := new(ThrottleError); errors.As(err, &throttleErr) {
+		return false
+	}
+
+	if apiErr := new(APIError); errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		default:
+			return false
+		}
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
+
+// shouldRetry returns whether a request which failed with the given error
+// at the given attempt (starting from 1) should be sent again.
+func (rp *RetryPolicy) shouldRetry(attempt int, err error) bool {
+	if rp == nil || err == nil {
+		return false
+	}
+
+	maxAttempts := rp.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultRetryMaxAttempts
+	}
+
+	if attempt >= maxAttempts {
+		return false
+	}
+
+	if rp.Retryable != nil {
+		return rp.Retryable(err)
+	}
+
+	return IsRetryableError(err)
+}
+
+// backoff returns the delay to wait after the given attempt (starting from 1) failed.
+func (rp *RetryPolicy) backoff(attempt int) time.Duration {
+	base, maxBackoff := rp.BaseBackoff, rp.MaxBackoff
+	if base <= 0 {
+		base = defaultRetryBaseBackoff
+	}
+
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRetryMaxBackoff
+	}
+
+	delay := base
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	delay = min(delay, maxBackoff)
+
+	if jitter := min(max(rp.Jitter, 0), 1); jitter > 0 {
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay)) //nolint:gosec
+	}
+
+	return delay
+}
@@ -0,0 +1,128 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	t.Parallel()
+
+	policy := &RetryPolicy{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	expectedDelays := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+
+	for i, expectedDelay := range expectedDelays {
+		if delay := policy.backoff(i + 1); delay != expectedDelay {
+			t.Errorf("Unexpected backoff for attempt %d: want %s, got %s", i+1, expectedDelay, delay)
+		}
+	}
+
+	policy.Jitter = 0.5
+
+	for attempt := 1; attempt <= 5; attempt++ {
+		delay := policy.backoff(attempt)
+		if delay < expectedDelays[attempt-1]/2 || delay > expectedDelays[attempt-1] {
+			t.Errorf("Backoff for attempt %d is out of jitter bounds: %s", attempt, delay)
+		}
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		err       error
+		retryable bool
+	}{
+		{err: nil, retryable: false},
+		{err: &APIError{StatusCode: http.StatusBadGateway}, retryable: true},
+		{err: &APIError{StatusCode: http.StatusServiceUnavailable}, retryable: true},
+		{err: &APIError{StatusCode: http.StatusGatewayTimeout}, retryable: true},
+		{err: &APIError{StatusCode: http.StatusInternalServerError}, retryable: false},
+		{err: &APIError{StatusCode: http.StatusBadRequest}, retryable: false},
+		{err: &ThrottleError{APIError: &APIError{StatusCode: http.StatusTooManyRequests}}, retryable: false},
+		{err: fmt.Errorf("request execution failed: %w", &net.OpError{Op: "dial", Err: errUnreadable}), retryable: true},
+		{err: fmt.Errorf("request execution failed: %w", context.Canceled), retryable: false},
+	}
+
+	for _, tc := range cases {
+		if retryable := IsRetryableError(tc.err); retryable != tc.retryable {
+			t.Errorf("Unexpected retryability of %v: want %t, got %t", tc.err, tc.retryable, retryable)
+		}
+	}
+}
+
+func TestClientDoRetry(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name             string
+		statusCodes      []int
+		expectedStatus   int
+		expectedRequests int
+	}{
+		{
+			name:             "success after retries",
+			statusCodes:      []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 3,
+		},
+		{
+			name:             "too many failures",
+			statusCodes:      []int{http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusGatewayTimeout},
+			expectedStatus:   http.StatusGatewayTimeout,
+			expectedRequests: 3,
+		},
+		{
+			name:             "not retryable",
+			statusCodes:      []int{http.StatusNotFound, http.StatusOK},
+			expectedStatus:   http.StatusNotFound,
+			expectedRequests: 1,
+		},
+	}
+
+	for _, testCase := range cases {
+		tc := testCase
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			client, requestCounter := makeClientMockForDo(t, func(*http.Request) (int, []byte, error) {
+				statusCode := tc.statusCodes[calls]
+				calls++
+
+				return statusCode, []byte(`{}`), nil
+			}, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond}))
+
+			statusCode, _, _ := client.Do(context.Background(), http.MethodGet, "/v1/resource/", nil, false, nil)
+			if statusCode != tc.expectedStatus {
+				t.Fatalf("Expected status code to be %d, got %d", tc.expectedStatus, statusCode)
+			}
+
+			if requestCounter["/v1/resource/"] != tc.expectedRequests {
+				t.Fatalf("Expected %d requests, got %d", tc.expectedRequests, requestCounter["/v1/resource/"])
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+)
+
+// flightCall is a request execution shared by concurrent callers.
+type flightCall struct {
+	done       chan struct{}
+	statusCode int
+	body       []byte
+	err        error
+	// The number of callers still waiting for the result.
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// requestGroup deduplicates the concurrent executions of identical requests.
+type requestGroup struct {
+	l     sync.Mutex
+	calls map[string]*flightCall
+}
+
+// flightKey returns the key identifying the given request in a requestGroup,
+// or false if the request isn't idempotent and mustn't be shared.
+func flightKey(req *http.Request, authenticated bool) (string, bool) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return "", false
+	}
+
+	key := req.Method + " " + req.URL.String() + " " + req.Header.Get("X-Bleemeo-Account")
+	if authenticated {
+		key += " authenticated"
+	}
+
+	return key, true
+}
+
+// do executes fn, unless an execution with the same key is already in flight,
+// in which case its result is awaited instead.
+// The shared execution runs with a context detached from the one of its callers,
+// which is only canceled once all the callers have given up waiting for it.
+// Each caller receives its own copy of the response body.
+func (g *requestGroup) do(
+	ctx context.Context, key string, fn func(ctx context.Context) (int, []byte, error),
+) (int, []byte, error) {
+	g.l.Lock()
+
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+
+	call, ok := g.calls[key]
+	if ok {
+		call.waiters++
+	} else {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &flightCall{
+			done:    make(chan struct{}),
+			waiters: 1,
+			cancel:  cancel,
+		}
+		g.calls[key] = call
+
+		go func() {
+			call.statusCode, call.body, call.err = fn(callCtx)
+
+			g.l.Lock()
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+			g.l.Unlock()
+
+			cancel()
+			close(call.done)
+		}()
+	}
+
+	g.l.Unlock()
+
+	select {
+	case <-call.done:
+		return call.statusCode, bytes.Clone(call.body), call.err
+	case <-ctx.Done():
+		g.l.Lock()
+		defer g.l.Unlock()
+
+		call.waiters--
+		if call.waiters == 0 {
+			// Nobody is interested in the result anymore.
+			call.cancel()
+
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+		}
+
+		return 0, nil, ctx.Err() //nolint:wrapcheck
+	}
+}
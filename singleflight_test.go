@@ -0,0 +1,199 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitForWaiters waits until the given number of callers are waiting for the in-flight request with the given key.
+func waitForWaiters(t *testing.T, g *requestGroup, key string, waiters int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+
+	for time.Now().Before(deadline) {
+		g.l.Lock()
+		call, ok := g.calls[key]
+		current := 0
+
+		if ok {
+			current = call.waiters
+		}
+		g.l.Unlock()
+
+		if current == waiters {
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("Timed out waiting for %d waiters", waiters)
+}
+
+func TestRequestDeduplication(t *testing.T) {
+	t.Parallel()
+
+	const key = "GET " + defaultEndpoint + "/v1/resource/ "
+
+	t.Run("shared request", func(t *testing.T) {
+		t.Parallel()
+
+		const callers = 5
+
+		release := make(chan struct{})
+		handler := func(*http.Request) (int, []byte, error) {
+			<-release
+
+			return http.StatusOK, []byte(`{"id": "1"}`), nil
+		}
+
+		client, requestCounter := makeClientMockForDo(t, handler, WithRequestDeduplication())
+
+		var wg sync.WaitGroup
+
+		for range callers {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				_, body, err := client.Do(context.Background(), http.MethodGet, "/v1/resource/", nil, false, nil)
+				if err != nil {
+					t.Error("Unexpected error:", err)
+				}
+
+				if string(body) != `{"id": "1"}` {
+					t.Errorf("Unexpected body %q", body)
+				}
+			}()
+		}
+
+		waitForWaiters(t, client.requestGroup, key, callers)
+		close(release)
+		wg.Wait()
+
+		if requestCounter["/v1/resource/"] != 1 {
+			t.Fatalf("Expected a single request to be sent, got %d", requestCounter["/v1/resource/"])
+		}
+	})
+
+	t.Run("caller cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		release := make(chan struct{})
+		handler := func(*http.Request) (int, []byte, error) {
+			<-release
+
+			return http.StatusOK, []byte(`{}`), nil
+		}
+
+		client, requestCounter := makeClientMockForDo(t, handler, WithRequestDeduplication())
+		ctx, cancel := context.WithCancel(context.Background())
+		errCh := make(chan error)
+
+		go func() {
+			_, _, err := client.Do(ctx, http.MethodGet, "/v1/resource/", nil, false, nil)
+			errCh <- err
+		}()
+
+		waitForWaiters(t, client.requestGroup, key, 1)
+
+		go func() {
+			_, _, err := client.Do(context.Background(), http.MethodGet, "/v1/resource/", nil, false, nil)
+			errCh <- err
+		}()
+
+		waitForWaiters(t, client.requestGroup, key, 2)
+		cancel()
+
+		if err := <-errCh; !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected error %v, got %v", context.Canceled, err)
+		}
+
+		close(release)
+
+		if err := <-errCh; err != nil {
+			t.Fatal("Unexpected error for the remaining caller:", err)
+		}
+
+		if requestCounter["/v1/resource/"] != 1 {
+			t.Fatalf("Expected a single request to be sent, got %d", requestCounter["/v1/resource/"])
+		}
+	})
+
+	t.Run("all callers canceled", func(t *testing.T) {
+		t.Parallel()
+
+		requestCanceled := make(chan struct{})
+		handler := func(r *http.Request) (int, []byte, error) {
+			<-r.Context().Done()
+			close(requestCanceled)
+
+			return 0, nil, r.Context().Err()
+		}
+
+		client, _ := makeClientMockForDo(t, handler, WithRequestDeduplication())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, _, err := client.Do(ctx, http.MethodGet, "/v1/resource/", nil, false, nil)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected error %v, got %v", context.DeadlineExceeded, err)
+		}
+
+		select {
+		case <-requestCanceled:
+		case <-time.After(time.Second):
+			t.Fatal("The shared request hasn't been canceled")
+		}
+	})
+
+	t.Run("non-idempotent requests", func(t *testing.T) {
+		t.Parallel()
+
+		client, requestCounter := makeClientMockForDo(t, okHandler, WithRequestDeduplication())
+
+		var wg sync.WaitGroup
+
+		for range 3 {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				_, _, err := client.Do(context.Background(), http.MethodPost, "/v1/resource/", nil, false, nil)
+				if err != nil {
+					t.Error("Unexpected error:", err)
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		if requestCounter["/v1/resource/"] != 3 {
+			t.Fatalf("Expected 3 requests to be sent, got %d", requestCounter["/v1/resource/"])
+		}
+	})
+}
@@ -0,0 +1,140 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// throttleQueue holds the requests sent while the client is throttled,
+// and releases them in their arrival order once the throttle deadline has passed.
+// Waiters are released one at a time: the next one is only released
+// once the previous one has started sending its request.
+type throttleQueue struct {
+	l       sync.Mutex
+	waiters []chan func()
+	timer   *time.Timer
+}
+
+// throttleReleaseKey is the context key holding the function
+// releasing the next waiter of the throttle queue.
+type throttleReleaseKey struct{}
+
+// wait blocks until the deadline returned by the given function has passed,
+// or returns an error if the context is done before.
+// The deadline is checked again before releasing the waiters, in case it has been extended.
+// Once released, the caller must call the returned function when it starts sending its request,
+// so that the next waiter is released.
+func (tq *throttleQueue) wait(ctx context.Context, deadline func() time.Time) (func(), error) {
+	ch := make(chan func(), 1)
+
+	tq.l.Lock()
+
+	tq.waiters = append(tq.waiters, ch)
+
+	if tq.timer == nil {
+		tq.timer = time.AfterFunc(time.Until(deadline()), func() { tq.release(deadline) })
+	}
+
+	tq.l.Unlock()
+
+	select {
+	case next := <-ch:
+		return next, nil
+	case <-ctx.Done():
+		tq.l.Lock()
+
+		if idx := slices.Index(tq.waiters, ch); idx >= 0 {
+			tq.waiters = slices.Delete(tq.waiters, idx, idx+1)
+			tq.l.Unlock()
+
+			return nil, ctx.Err() //nolint:wrapcheck
+		}
+
+		tq.l.Unlock()
+
+		// We have been released at the same time, so the next waiter must be released in our place.
+		(<-ch)()
+
+		return nil, ctx.Err() //nolint:wrapcheck
+	}
+}
+
+// release releases the first waiter, unless the deadline has been extended.
+func (tq *throttleQueue) release(deadline func() time.Time) {
+	tq.l.Lock()
+	defer tq.l.Unlock()
+
+	if delay := time.Until(deadline()); delay > 0 {
+		tq.timer.Reset(delay)
+
+		return
+	}
+
+	tq.releaseFirstLocked(deadline)
+}
+
+// releaseFirstLocked hands the turn to the first waiter, or resets the queue if there is none.
+// The queue lock must be held.
+func (tq *throttleQueue) releaseFirstLocked(deadline func() time.Time) {
+	if len(tq.waiters) == 0 {
+		tq.timer = nil
+
+		return
+	}
+
+	ch := tq.waiters[0]
+	tq.waiters = tq.waiters[1:]
+
+	var once sync.Once
+
+	ch <- func() {
+		once.Do(func() { tq.releaseNext(deadline) })
+	}
+}
+
+// releaseNext releases the next waiter, or waits for the deadline again
+// if the client has been throttled in the meantime.
+func (tq *throttleQueue) releaseNext(deadline func() time.Time) {
+	tq.l.Lock()
+	defer tq.l.Unlock()
+
+	if delay := time.Until(deadline()); delay > 0 && len(tq.waiters) > 0 {
+		tq.timer.Reset(delay)
+
+		return
+	}
+
+	tq.releaseFirstLocked(deadline)
+}
+
+// contextWithThrottleRelease returns a copy of the given context holding the function
+// releasing the next waiter of the throttle queue.
+func contextWithThrottleRelease(ctx context.Context, next func()) context.Context {
+	return context.WithValue(ctx, throttleReleaseKey{}, next)
+}
+
+// releaseThrottleQueue releases the next waiter of the throttle queue,
+// if the request of the given context was held by it.
+func releaseThrottleQueue(ctx context.Context) {
+	if next, ok := ctx.Value(throttleReleaseKey{}).(func()); ok {
+		next()
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func okHandler(*http.Request) (int, []byte, error) {
+	return http.StatusOK, []byte(`{}`), nil
+}
+
+func TestThrottleQueue(t *testing.T) {
+	t.Parallel()
+
+	const throttleDelay = 50 * time.Millisecond
+
+	t.Run("without queue", func(t *testing.T) {
+		t.Parallel()
+
+		client, requestCounter := makeClientMockForDo(t, okHandler)
+		client.throttleDeadline = time.Now().Add(throttleDelay)
+
+		_, _, err := client.Do(context.Background(), http.MethodGet, "/v1/resource/", nil, false, nil)
+		if throttleErr := new(ThrottleError); !errors.As(err, &throttleErr) {
+			t.Fatalf("Expected a ThrottleError, got %v", err)
+		}
+
+		if requestCounter["/v1/resource/"] != 0 {
+			t.Fatalf("Expected no request to be sent, got %d", requestCounter["/v1/resource/"])
+		}
+	})
+
+	t.Run("with queue", func(t *testing.T) {
+		t.Parallel()
+
+		const callers = 3
+
+		client, requestCounter := makeClientMockForDo(t, okHandler, WithThrottleQueue())
+		client.throttleDeadline = time.Now().Add(throttleDelay)
+		start := time.Now()
+
+		var wg sync.WaitGroup
+
+		for range callers {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				_, _, err := client.Do(context.Background(), http.MethodGet, "/v1/resource/", nil, false, nil)
+				if err != nil {
+					t.Error("Unexpected error:", err)
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		if elapsed := time.Since(start); elapsed < throttleDelay {
+			t.Fatalf("Requests were sent before the throttle deadline, after %s", elapsed)
+		}
+
+		if requestCounter["/v1/resource/"] != callers {
+			t.Fatalf("Expected %d requests to be sent, got %d", callers, requestCounter["/v1/resource/"])
+		}
+	})
+
+	t.Run("arrival order", func(t *testing.T) {
+		t.Parallel()
+
+		const callers = 5
+
+		var (
+			l     sync.Mutex
+			order []string
+		)
+
+		client, _ := makeClientMockForDo(t, func(r *http.Request) (int, []byte, error) {
+			l.Lock()
+			defer l.Unlock()
+
+			order = append(order, r.URL.Query().Get("caller"))
+
+			return http.StatusOK, []byte(`{}`), nil
+		}, WithThrottleQueue())
+		// Leave enough time for all the callers to be queued.
+		client.throttleDeadline = time.Now().Add(4 * throttleDelay)
+
+		var (
+			wg       sync.WaitGroup
+			expected []string
+		)
+
+		for i := range callers {
+			caller := strconv.Itoa(i)
+			expected = append(expected, caller)
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				params := url.Values{"caller": {caller}}
+
+				_, _, err := client.Do(context.Background(), http.MethodGet, "/v1/resource/", params, false, nil)
+				if err != nil {
+					t.Error("Unexpected error:", err)
+				}
+			}()
+
+			// Wait for the caller to be queued before starting the next one.
+			for queued := false; !queued; {
+				client.throttleQueue.l.Lock()
+				queued = len(client.throttleQueue.waiters) == i+1
+				client.throttleQueue.l.Unlock()
+
+				time.Sleep(time.Millisecond)
+			}
+		}
+
+		wg.Wait()
+
+		if diff := cmp.Diff(expected, order); diff != "" {
+			t.Fatalf("Requests weren't sent in their arrival order (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("delay above max auto retry delay", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := makeClientMockForDo(t, okHandler, WithThrottleQueue(), WithThrottleMaxAutoRetryDelay(time.Millisecond))
+		client.throttleDeadline = time.Now().Add(time.Minute)
+
+		_, _, err := client.Do(context.Background(), http.MethodGet, "/v1/resource/", nil, false, nil)
+		if throttleErr := new(ThrottleError); !errors.As(err, &throttleErr) {
+			t.Fatalf("Expected a ThrottleError, got %v", err)
+		}
+	})
+
+	t.Run("context cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		client, requestCounter := makeClientMockForDo(t, okHandler, WithThrottleQueue())
+		client.throttleDeadline = time.Now().Add(time.Minute)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, _, err := client.Do(ctx, http.MethodGet, "/v1/resource/", nil, false, nil)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected error %v, got %v", context.DeadlineExceeded, err)
+		}
+
+		if requestCounter["/v1/resource/"] != 0 {
+			t.Fatalf("Expected no request to be sent, got %d", requestCounter["/v1/resource/"])
+		}
+	})
+
+	t.Run("extended deadline", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			l        sync.Mutex
+			tq       throttleQueue
+			deadline = time.Now().Add(throttleDelay / 2)
+		)
+
+		getDeadline := func() time.Time {
+			l.Lock()
+			defer l.Unlock()
+
+			return deadline
+		}
+
+		go func() {
+			time.Sleep(throttleDelay / 4)
+
+			l.Lock()
+			deadline = deadline.Add(throttleDelay / 2)
+			l.Unlock()
+		}()
+
+		start := time.Now()
+
+		next, err := tq.wait(context.Background(), getDeadline)
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+
+		next()
+
+		if elapsed := time.Since(start); elapsed < throttleDelay*3/4 {
+			t.Fatalf("Waiter was released before the extended deadline, after %s", elapsed)
+		}
+	})
+}
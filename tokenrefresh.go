@@ -0,0 +1,145 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// The maximum duration of a background token renewal.
+const backgroundRefreshTimeout = time.Minute
+
+// scheduleRefresh schedules the background renewal of the current token, if enabled,
+// replacing the previously scheduled one. It must be called with the lock held.
+func (ap *authenticationProvider) scheduleRefresh() {
+	ap.stopRefresh()
+
+	if ap.refreshMargin <= 0 || ap.refreshClosed || ap.token == nil || ap.token.Expiry.IsZero() {
+		return
+	}
+
+	remaining := time.Until(ap.token.Expiry)
+	delay := remaining - ap.refreshMargin
+
+	if delay <= 0 {
+		// The token lives less than the margin, renew it halfway to avoid renewing it continuously.
+		delay = remaining / 2
+	}
+
+	generation := ap.refreshGeneration
+
+	ap.refreshTimer = time.AfterFunc(delay, func() {
+		ap.backgroundRefresh(generation)
+	})
+}
+
+// closeRefresh stops the background renewal of the token for good, without revoking the token.
+func (ap *authenticationProvider) closeRefresh() {
+	ap.l.Lock()
+	defer ap.l.Unlock()
+
+	ap.refreshClosed = true
+
+	ap.stopRefresh()
+}
+
+// stopRefresh cancels the scheduled renewal of the token, if any. It must be called with the lock held.
+func (ap *authenticationProvider) stopRefresh() {
+	// A renewal which is already waiting for the lock must not proceed.
+	ap.refreshGeneration++
+
+	if ap.refreshTimer != nil {
+		ap.refreshTimer.Stop()
+		ap.refreshTimer = nil
+	}
+}
+
+// backgroundRefresh renews the current token, unless the renewal of the given generation has been canceled
+// in the meantime. The refresh token is used if possible, otherwise a new token is fetched
+// when credentials are available. The lock isn't held while the token is being retrieved,
+// so that requests can still use the current token, which is only replaced if the renewal
+// hasn't been canceled in the meantime.
+func (ap *authenticationProvider) backgroundRefresh(generation int) {
+	ctx, cancel := context.WithTimeout(context.Background(), backgroundRefreshTimeout)
+	defer cancel()
+
+	ap.l.Lock()
+
+	if ap.refreshGeneration != generation || ap.token == nil {
+		ap.l.Unlock()
+
+		return // The token has been renewed or revoked
+	}
+
+	refreshToken := ap.token.RefreshToken
+
+	ap.l.Unlock()
+
+	tk, logMsg, err := ap.renewToken(ctx, refreshToken)
+	if err != nil {
+		// The current token is kept, and will be renewed by the next request once expired.
+		orDiscard(ap.logger).LogAttrs(ctx, slog.LevelWarn, "Failed to renew the OAuth token in the background",
+			slog.Any("error", err))
+
+		// The callback is called without the lock, so that it can use the client.
+		if ap.refreshErrorCallback != nil {
+			ap.refreshErrorCallback(err)
+		}
+
+		return
+	}
+
+	ap.l.Lock()
+	defer ap.l.Unlock()
+
+	if ap.refreshGeneration != generation {
+		return // The token has been renewed or revoked during the renewal
+	}
+
+	ap.token = tk
+
+	ap.tokenRetrieved(ctx, logMsg)
+}
+
+// renewToken retrieves a new token with the given refresh token, if any,
+// or with the credentials if the refresh failed. It doesn't need the lock to be held.
+func (ap *authenticationProvider) renewToken(
+	ctx context.Context, refreshToken string,
+) (tk *oauth2.Token, logMsg string, err error) {
+	err = errTokenHasNoRefresh
+	logMsg = "Refreshed the OAuth token in the background"
+
+	if refreshToken != "" {
+		tk, err = ap.tracedRefreshToken(ctx, refreshToken)
+	}
+
+	if err != nil && !ap.refreshOnly && ap.newToken != nil {
+		if refreshToken != "" {
+			orDiscard(ap.logger).LogAttrs(ctx, slog.LevelWarn, "Failed to refresh the OAuth token, fetching a new one",
+				slog.Any("error", err))
+		}
+
+		logMsg = "Fetched a new OAuth token in the background"
+		tk, err = ap.tracedNewToken(ctx)
+	}
+
+	return tk, logMsg, err
+}
@@ -0,0 +1,281 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// makeGrantMockHandler returns a token handler which reports the grant type of each request
+// on the returned channel, and fails the grants for which fail returns true.
+func makeGrantMockHandler(t *testing.T, fail func(grantType string) bool) (mockHandler, <-chan string) {
+	t.Helper()
+
+	grants := make(chan string, 10)
+	handler := func(r *http.Request) (int, []byte, error) {
+		if err := r.ParseForm(); err != nil {
+			t.Error("Failed to parse token request:", err)
+		}
+
+		grantType := r.PostForm.Get("grant_type")
+
+		select {
+		case grants <- grantType:
+		default: // Don't block the client once the test is over
+		}
+
+		if fail(grantType) {
+			return http.StatusBadRequest, []byte(`{"error": "invalid_grant"}`), nil
+		}
+
+		// Tokens are short-lived, so that they are renewed quickly.
+		return http.StatusOK, []byte(
+			`{"access_token": "access", "expires_in": 1, "token_type": "Bearer", "refresh_token": "refresh"}`,
+		), nil
+	}
+
+	return handler, grants
+}
+
+func expectGrant(t *testing.T, grants <-chan string, expectedGrantType string) {
+	t.Helper()
+
+	select {
+	case grantType := <-grants:
+		if grantType != expectedGrantType {
+			t.Fatalf("Expected a %q grant, got %q", expectedGrantType, grantType)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for a %q grant", expectedGrantType)
+	}
+}
+
+func TestBackgroundTokenRefresh(t *testing.T) {
+	t.Parallel()
+
+	const margin = 800 * time.Millisecond
+
+	t.Run("refresh", func(t *testing.T) {
+		t.Parallel()
+
+		authHandler, grants := makeGrantMockHandler(t, func(string) bool { return false })
+		expectedAccessTk := "access"
+
+		client, _, err := makeClientMockForAuth(t, authHandler, okHandler, &expectedAccessTk,
+			WithCredentials("u", "p"), WithBackgroundTokenRefresh(margin, nil))
+		if err != nil {
+			t.Fatal("Failed to init client:", err)
+		}
+
+		if _, err = client.GetToken(t.Context()); err != nil {
+			t.Fatal("Failed to get token:", err)
+		}
+
+		expectGrant(t, grants, "password")
+		// Without any request, the token is refreshed before its expiry, and again after that.
+		expectGrant(t, grants, "refresh_token")
+		expectGrant(t, grants, "refresh_token")
+
+		if err = client.Logout(t.Context()); err != nil {
+			t.Fatal("Failed to logout:", err)
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+		t.Parallel()
+
+		authHandler, grants := makeGrantMockHandler(t, func(string) bool { return false })
+		expectedAccessTk := "access"
+
+		client, _, err := makeClientMockForAuth(t, authHandler, okHandler, &expectedAccessTk,
+			WithCredentials("u", "p"), WithBackgroundTokenRefresh(margin, nil))
+		if err != nil {
+			t.Fatal("Failed to init client:", err)
+		}
+
+		if _, err = client.GetToken(t.Context()); err != nil {
+			t.Fatal("Failed to get token:", err)
+		}
+
+		expectGrant(t, grants, "password")
+		expectGrant(t, grants, "refresh_token")
+
+		client.Close()
+
+		// Otherwise, the token would have been renewed again within this delay.
+		select {
+		case grantType := <-grants:
+			t.Fatalf("Unexpected %q grant after the client has been closed", grantType)
+		case <-time.After(1500 * time.Millisecond):
+		}
+	})
+
+	t.Run("fallback to new token", func(t *testing.T) {
+		t.Parallel()
+
+		authHandler, grants := makeGrantMockHandler(t, func(grantType string) bool { return grantType == "refresh_token" })
+		expectedAccessTk := "access"
+
+		client, _, err := makeClientMockForAuth(t, authHandler, okHandler, &expectedAccessTk,
+			WithCredentials("u", "p"), WithBackgroundTokenRefresh(margin, nil))
+		if err != nil {
+			t.Fatal("Failed to init client:", err)
+		}
+
+		if _, err = client.GetToken(t.Context()); err != nil {
+			t.Fatal("Failed to get token:", err)
+		}
+
+		expectGrant(t, grants, "password")
+		expectGrant(t, grants, "refresh_token")
+		expectGrant(t, grants, "password")
+
+		if err = client.Logout(t.Context()); err != nil {
+			t.Fatal("Failed to logout:", err)
+		}
+	})
+
+	t.Run("slow refresh", func(t *testing.T) {
+		t.Parallel()
+
+		refreshStarted := make(chan struct{})
+		releaseRefresh := make(chan struct{})
+		authHandler := func(r *http.Request) (int, []byte, error) {
+			if err := r.ParseForm(); err != nil {
+				t.Error("Failed to parse token request:", err)
+			}
+
+			if r.PostForm.Get("grant_type") == "refresh_token" {
+				close(refreshStarted)
+				<-releaseRefresh
+
+				return http.StatusOK, []byte(
+					`{"access_token": "refreshed", "expires_in": 60, "token_type": "Bearer", "refresh_token": "refresh"}`,
+				), nil
+			}
+
+			return http.StatusOK, []byte(
+				`{"access_token": "access", "expires_in": 60, "token_type": "Bearer", "refresh_token": "refresh"}`,
+			), nil
+		}
+		expectedAccessTk := "access"
+
+		// The token is renewed shortly after being fetched, while it's still valid.
+		client, _, err := makeClientMockForAuth(t, authHandler, okHandler, &expectedAccessTk,
+			WithCredentials("u", "p"), WithBackgroundTokenRefresh(time.Minute-200*time.Millisecond, nil))
+		if err != nil {
+			t.Fatal("Failed to init client:", err)
+		}
+
+		if _, err = client.GetToken(t.Context()); err != nil {
+			t.Fatal("Failed to get token:", err)
+		}
+
+		select {
+		case <-refreshStarted:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for the background refresh")
+		}
+
+		// The current token remains available while the refresh is in flight.
+		tokens := make(chan *oauth2.Token, 1)
+
+		go func() {
+			tk, err := client.GetToken(t.Context())
+			if err != nil {
+				t.Error("Failed to get token during the refresh:", err)
+			}
+
+			tokens <- tk
+		}()
+
+		select {
+		case tk := <-tokens:
+			if tk != nil && tk.AccessToken != "access" {
+				t.Errorf("Expected the current token during the refresh, got %q", tk.AccessToken)
+			}
+		case <-time.After(time.Second):
+			t.Error("Getting the token was blocked by the background refresh")
+		}
+
+		close(releaseRefresh)
+
+		if err = client.Logout(t.Context()); err != nil {
+			t.Fatal("Failed to logout:", err)
+		}
+	})
+
+	t.Run("error callback", func(t *testing.T) {
+		t.Parallel()
+
+		refreshCount := 0
+		authHandler, grants := makeGrantMockHandler(t, func(string) bool {
+			refreshCount++ // Only the first refresh succeeds
+
+			return refreshCount > 1
+		})
+		refreshErrs := make(chan error, 1)
+		expectedAccessTk := "access"
+
+		var client *Client
+
+		// The callback is called without the lock held, so it can use the client.
+		onError := func(err error) {
+			if !client.authProvider.l.TryLock() {
+				t.Error("The error callback was called with the lock held")
+			} else {
+				client.authProvider.l.Unlock()
+			}
+
+			refreshErrs <- err
+		}
+
+		client, _, err := makeClientMockForAuth(t, authHandler, okHandler, &expectedAccessTk,
+			WithInitialOAuthRefreshToken("initial-refresh"), WithBackgroundTokenRefresh(margin, onError))
+		if err != nil {
+			t.Fatal("Failed to init client:", err)
+		}
+
+		if _, err = client.GetToken(t.Context()); err != nil {
+			t.Fatal("Failed to get token:", err)
+		}
+
+		expectGrant(t, grants, "refresh_token")
+		expectGrant(t, grants, "refresh_token")
+
+		select {
+		case err = <-refreshErrs:
+			if retErr := new(oauth2.RetrieveError); !errors.As(err, &retErr) {
+				t.Fatalf("Expected an OAuth retrieve error, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for the refresh error")
+		}
+
+		// Refresh-only clients don't fall back to fetching a new token.
+		select {
+		case grantType := <-grants:
+			t.Fatalf("Unexpected %q grant after a failed background refresh", grantType)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+}
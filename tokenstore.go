@@ -0,0 +1,129 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/oauth2"
+)
+
+// A TokenStore persists the OAuth token of a Client, so that it can be reused by later instances,
+// e.g. by successive runs of a short-lived program, instead of fetching a new token each time.
+type TokenStore interface {
+	// Load returns the stored token, or nil if no token has been stored.
+	Load(ctx context.Context) (*oauth2.Token, error)
+	// Save stores the given token, replacing the previous one.
+	Save(ctx context.Context, token *oauth2.Token) error
+	// Delete removes the stored token, if any.
+	Delete(ctx context.Context) error
+}
+
+// A FileTokenStore is a TokenStore which keeps the token in a JSON file,
+// only readable by its owner. The file is written atomically,
+// so concurrent processes never read a partially written token.
+//
+// Since the token is bound to the credentials it has been retrieved with,
+// a different file should be used for each account and OAuth client.
+type FileTokenStore struct {
+	path string
+}
+
+// NewFileTokenStore returns a FileTokenStore which keeps the token in the file at the given path.
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+// Load returns the token stored in the file, or nil if the file doesn't exist.
+func (s *FileTokenStore) Load(context.Context) (*oauth2.Token, error) {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil //nolint:nilnil
+		}
+
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	var token oauth2.Token
+
+	if err = json.Unmarshal(content, &token); err != nil {
+		return nil, &JSONUnmarshalError{
+			jsonError: &jsonError{
+				Err:      err,
+				DataKind: JsonErrorDataKind_Token,
+				Data:     nil, // Don't keep the secrets in the error
+			},
+		}
+	}
+
+	return &token, nil
+}
+
+// Save writes the given token to a temporary file, then renames it to the path of the store.
+func (s *FileTokenStore) Save(_ context.Context, token *oauth2.Token) error {
+	content, err := json.Marshal(token)
+	if err != nil {
+		return &JSONMarshalError{
+			jsonError: &jsonError{
+				Err:      err,
+				DataKind: JsonErrorDataKind_Token,
+			},
+		}
+	}
+
+	// The temporary file is created with the 0600 permissions.
+	tmpFile, err := os.CreateTemp(filepath.Dir(s.path), "."+filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create token file: %w", err)
+	}
+
+	_, err = tmpFile.Write(content)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), s.path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmpFile.Name())
+
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the token file, if it exists.
+func (s *FileTokenStore) Delete(context.Context) error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete token file: %w", err)
+	}
+
+	return nil
+}
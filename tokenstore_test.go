@@ -0,0 +1,285 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/oauth2"
+)
+
+func TestFileTokenStore(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "token.json")
+	store := NewFileTokenStore(path)
+	cmpOpts := cmp.Options{cmpopts.IgnoreUnexported(oauth2.Token{}), cmpopts.EquateApproxTime(time.Second)}
+
+	token, err := store.Load(t.Context())
+	if err != nil || token != nil {
+		t.Fatalf("Expected no token and no error from an empty store, got %v and %v", token, err)
+	}
+
+	for _, accessToken := range []string{"access-1", "access-2"} {
+		savedToken := &oauth2.Token{
+			AccessToken:  accessToken,
+			TokenType:    "Bearer",
+			RefreshToken: "refresh",
+			Expiry:       time.Now().Add(time.Hour),
+		}
+
+		if err = store.Save(t.Context(), savedToken); err != nil {
+			t.Fatal("Failed to save token:", err)
+		}
+
+		token, err = store.Load(t.Context())
+		if err != nil {
+			t.Fatal("Failed to load token:", err)
+		}
+
+		if diff := cmp.Diff(savedToken, token, cmpOpts); diff != "" {
+			t.Fatalf("Unexpected token (-want +got):\n%s", diff)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal("Failed to stat token file:", err)
+	}
+
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("Expected token file permissions to be 0600, got %#o", perm)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal("Failed to read directory:", err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("Expected only the token file to remain, got %d files", len(entries))
+	}
+
+	for range 2 { // Deleting a missing token isn't an error
+		if err = store.Delete(t.Context()); err != nil {
+			t.Fatal("Failed to delete token:", err)
+		}
+	}
+
+	token, err = store.Load(t.Context())
+	if err != nil || token != nil {
+		t.Fatalf("Expected no token and no error after deletion, got %v and %v", token, err)
+	}
+}
+
+func TestClientWithTokenStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stored token", func(t *testing.T) {
+		t.Parallel()
+
+		store := NewFileTokenStore(filepath.Join(t.TempDir(), "token.json"))
+		storedToken := &oauth2.Token{
+			AccessToken:  "stored-access",
+			TokenType:    "Bearer",
+			RefreshToken: "stored-refresh",
+			Expiry:       time.Now().Add(time.Hour),
+		}
+
+		if err := store.Save(t.Context(), storedToken); err != nil {
+			t.Fatal("Failed to save token:", err)
+		}
+
+		expectedAccessTk := "stored-access"
+
+		client, requestCounter, err := makeClientMockForAuth(
+			t, authMockHandler, okHandler, &expectedAccessTk, WithTokenStore(store),
+		)
+		if err != nil {
+			t.Fatal("Failed to init client:", err)
+		}
+
+		if _, err = client.Get(t.Context(), ResourceAgent, "<id>"); err != nil {
+			t.Fatal("Failed to execute request:", err)
+		}
+
+		if requestCounter[tokenPath] != 0 {
+			t.Fatalf("Expected the stored token to be used, but %d tokens were fetched", requestCounter[tokenPath])
+		}
+	})
+
+	t.Run("saved and deleted token", func(t *testing.T) {
+		t.Parallel()
+
+		store := NewFileTokenStore(filepath.Join(t.TempDir(), "token.json"))
+		expectedAccessTk := "access"
+
+		client, _, err := makeClientMockForAuth(
+			t, authMockHandler, okHandler, &expectedAccessTk, WithCredentials("u", "p"), WithTokenStore(store),
+		)
+		if err != nil {
+			t.Fatal("Failed to init client:", err)
+		}
+
+		if _, err = client.Get(t.Context(), ResourceAgent, "<id>"); err != nil {
+			t.Fatal("Failed to execute request:", err)
+		}
+
+		token, err := store.Load(t.Context())
+		if err != nil {
+			t.Fatal("Failed to load token:", err)
+		}
+
+		if token == nil || token.AccessToken != "access" || token.RefreshToken != "refresh" {
+			t.Fatalf("Expected the fetched token to be stored, got %v", token)
+		}
+
+		if err = client.Logout(t.Context()); err != nil {
+			t.Fatal("Failed to logout:", err)
+		}
+
+		token, err = store.Load(t.Context())
+		if err != nil || token != nil {
+			t.Fatalf("Expected the stored token to be deleted on logout, got %v and %v", token, err)
+		}
+	})
+
+	t.Run("logout without request", func(t *testing.T) {
+		t.Parallel()
+
+		store := NewFileTokenStore(filepath.Join(t.TempDir(), "token.json"))
+		storedToken := &oauth2.Token{
+			AccessToken:  "stored-access",
+			TokenType:    "Bearer",
+			RefreshToken: "stored-refresh",
+			Expiry:       time.Now().Add(-time.Hour), // Only the refresh token is still usable
+		}
+
+		if err := store.Save(t.Context(), storedToken); err != nil {
+			t.Fatal("Failed to save token:", err)
+		}
+
+		var revokedTokens []string
+
+		revokeHandler := func(r *http.Request) (int, []byte, error) {
+			if err := r.ParseForm(); err != nil {
+				return 0, nil, err
+			}
+
+			revokedTokens = append(revokedTokens, r.PostForm.Get("token_type_hint")+":"+r.PostForm.Get("token"))
+
+			return http.StatusOK, nil, nil
+		}
+
+		expectedAccessTk := ""
+
+		client, requestCounter, err := makeClientMockForAuth(
+			t, authMockHandler, revokeHandler, &expectedAccessTk, WithTokenStore(store),
+		)
+		if err != nil {
+			t.Fatal("Failed to init client:", err)
+		}
+
+		if err = client.Logout(t.Context()); err != nil {
+			t.Fatal("Failed to logout:", err)
+		}
+
+		if diff := cmp.Diff([]string{"refresh_token:stored-refresh"}, revokedTokens); diff != "" {
+			t.Fatalf("Unexpected revoked tokens (-want +got):\n%s", diff)
+		}
+
+		if requestCounter[tokenPath] != 0 {
+			t.Fatalf("Expected no token to be fetched, got %d", requestCounter[tokenPath])
+		}
+
+		token, err := store.Load(t.Context())
+		if err != nil || token != nil {
+			t.Fatalf("Expected the stored token to be deleted on logout, got %v and %v", token, err)
+		}
+	})
+
+	t.Run("failed revocation", func(t *testing.T) {
+		t.Parallel()
+
+		store := NewFileTokenStore(filepath.Join(t.TempDir(), "token.json"))
+		storedToken := &oauth2.Token{AccessToken: "stored-access", RefreshToken: "stored-refresh"}
+
+		if err := store.Save(t.Context(), storedToken); err != nil {
+			t.Fatal("Failed to save token:", err)
+		}
+
+		revokeHandler := func(*http.Request) (int, []byte, error) {
+			return http.StatusServiceUnavailable, nil, nil
+		}
+
+		expectedAccessTk := ""
+
+		client, _, err := makeClientMockForAuth(t, authMockHandler, revokeHandler, &expectedAccessTk, WithTokenStore(store))
+		if err != nil {
+			t.Fatal("Failed to init client:", err)
+		}
+
+		if err = client.Logout(t.Context()); !errors.Is(err, ErrTokenRevoke) {
+			t.Fatalf("Expected error %v, got %v", ErrTokenRevoke, err)
+		}
+
+		token, err := store.Load(t.Context())
+		if err != nil || token != nil {
+			t.Fatalf("Expected the stored token to be deleted despite the failed revocation, got %v and %v", token, err)
+		}
+	})
+
+	t.Run("empty store", func(t *testing.T) {
+		t.Parallel()
+
+		store := NewFileTokenStore(filepath.Join(t.TempDir(), "token.json"))
+		expectedAccessTk := ""
+
+		client, _, err := makeClientMockForAuth(t, authMockHandler, okHandler, &expectedAccessTk, WithTokenStore(store))
+		if err != nil {
+			t.Fatal("Failed to init client:", err)
+		}
+
+		_, err = client.Get(t.Context(), ResourceAgent, "<id>")
+		if !errors.Is(err, ErrNoAuthMeanProvided) {
+			t.Fatalf("Expected error %v, got %v", ErrNoAuthMeanProvided, err)
+		}
+	})
+}
+
+func TestFileTokenStoreInvalidContent(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "token.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal("Failed to write token file:", err)
+	}
+
+	_, err := NewFileTokenStore(path).Load(context.Background())
+	if jsonErr := new(JSONUnmarshalError); !errors.As(err, &jsonErr) || jsonErr.DataKind != JsonErrorDataKind_Token {
+		t.Fatalf("Expected a JSONUnmarshalError about the token, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const tracerName = "github.com/bleemeo/bleemeo-go"
+
+// Span attributes.
+const (
+	attrResource     = "bleemeo.resource"
+	attrMethod       = "http.request.method"
+	attrStatusCode   = "http.response.status_code"
+	attrURLPath      = "url.path"
+	attrRetryCount   = "bleemeo.retry_count"
+	attrThrottleWait = "bleemeo.throttle_wait_seconds"
+	attrGrantType    = "oauth.grant_type"
+)
+
+//nolint:gochecknoglobals
+var noopTracer = noop.NewTracerProvider().Tracer(tracerName)
+
+// startSpan starts a new span with the given tracer, or a non-recording span if the tracer is nil.
+func startSpan(
+	ctx context.Context, tracer trace.Tracer, name string, kind trace.SpanKind, attrs ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	if tracer == nil {
+		tracer = noopTracer
+	}
+
+	return tracer.Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+}
+
+// endSpan records the outcome of the operation of the given span, then ends it.
+// The status code is ignored if it is zero, and marks the span as failed if it is 4xx or 5xx.
+func endSpan(span trace.Span, statusCode int, err error, attrs ...attribute.KeyValue) {
+	if statusCode != 0 {
+		span.SetAttributes(attribute.Int(attrStatusCode, statusCode))
+	}
+
+	span.SetAttributes(attrs...)
+
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case statusCode >= http.StatusBadRequest:
+		span.SetStatus(codes.Error, http.StatusText(statusCode))
+	}
+
+	span.End()
+}
@@ -0,0 +1,123 @@
+// Copyright 2015-2025 Bleemeo
+//
+// bleemeo.com an infrastructure monitoring solution in the Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bleemeo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	var traceParents []string
+
+	requestCounter := make(map[string]int)
+	clientMock := &http.Client{
+		Transport: &transportMock{
+			handlers: map[string]mockHandler{
+				tokenPath: authMockHandler,
+				"/v1/agent/1/": func(r *http.Request) (int, []byte, error) {
+					traceParents = append(traceParents, r.Header.Get("Traceparent"))
+
+					if len(traceParents) == 1 {
+						return http.StatusServiceUnavailable, nil, nil
+					}
+
+					return http.StatusOK, []byte(`{"id": "1"}`), nil
+				},
+			},
+			counters: requestCounter,
+		},
+	}
+
+	client, err := NewClient(
+		WithCredentials("u", ""),
+		WithHTTPClient(clientMock),
+		WithTracerProvider(provider),
+		WithRetryPolicy(RetryPolicy{BaseBackoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatal("Failed to initialize client:", err)
+	}
+
+	_, err = client.Get(context.Background(), ResourceAgent, "1")
+	if err != nil {
+		t.Fatal("Failed to get agent:", err)
+	}
+
+	spans := exporter.GetSpans()
+	spansByName := make(map[string][]tracetest.SpanStub)
+
+	for _, span := range spans {
+		spansByName[span.Name] = append(spansByName[span.Name], span)
+	}
+
+	if len(spans) != 4 || len(spansByName["GET v1/agent/"]) != 1 ||
+		len(spansByName["HTTP GET"]) != 2 || len(spansByName["OAuth token fetch"]) != 1 {
+		t.Fatalf("Unexpected spans: %v", spansByName)
+	}
+
+	doSpan := spansByName["GET v1/agent/"][0]
+	expectedAttributes := []attribute.KeyValue{
+		attribute.String(attrResource, ResourceAgent),
+		attribute.String(attrMethod, http.MethodGet),
+		attribute.Int(attrStatusCode, http.StatusOK),
+		attribute.Int(attrRetryCount, 1),
+		attribute.Float64(attrThrottleWait, 0),
+	}
+
+	if diff := cmp.Diff(expectedAttributes, doSpan.Attributes, cmp.Comparer(attributeComparer)); diff != "" {
+		t.Fatalf("Unexpected Do span attributes (-want +got):\n%s", diff)
+	}
+
+	for i, requestSpan := range spansByName["HTTP GET"] {
+		if requestSpan.Parent.SpanID() != doSpan.SpanContext.SpanID() {
+			t.Errorf("Request span %d isn't a child of the Do span", i)
+		}
+
+		expectedTraceParent := "00-" + requestSpan.SpanContext.TraceID().String() +
+			"-" + requestSpan.SpanContext.SpanID().String() + "-01"
+		if traceParents[i] != expectedTraceParent {
+			t.Errorf("Unexpected traceparent header for request %d: want %q, got %q", i, expectedTraceParent, traceParents[i])
+		}
+	}
+
+	if failedSpan := spansByName["HTTP GET"][0]; failedSpan.Status.Code != codes.Error {
+		t.Errorf("Expected the status of the first request span to be an error, got %v", failedSpan.Status)
+	}
+
+	tokenSpan := spansByName["OAuth token fetch"][0]
+	if tokenSpan.Parent.SpanID() != spansByName["HTTP GET"][0].SpanContext.SpanID() {
+		t.Error("Token span isn't a child of the first request span")
+	}
+}
+
+func attributeComparer(x, y attribute.KeyValue) bool {
+	return x.Key == y.Key && x.Value.Emit() == y.Value.Emit()
+}
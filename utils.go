@@ -18,9 +18,11 @@ package bleemeo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
@@ -47,6 +49,79 @@ func JSONReaderFrom(body any) (io.Reader, error) {
 	return bytes.NewReader(data), nil
 }
 
+// replayableBody returns a reader to the given body, along with a function returning
+// a new reader to the same content, which can be used as [http.Request.GetBody].
+// Readers which [http.NewRequest] already knows how to replay are returned as is, with a nil function.
+// Seekable readers are replayed from their current offset, while others are fully buffered.
+func replayableBody(body io.Reader) (io.Reader, func() (io.ReadCloser, error), error) {
+	switch b := body.(type) {
+	case nil, *bytes.Buffer, *bytes.Reader, *strings.Reader:
+		return body, nil, nil
+	case io.ReadSeeker:
+		offset, err := b.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, nil, fmt.Errorf("can't get request body offset: %w", err)
+		}
+
+		getBody := func() (io.ReadCloser, error) {
+			_, err := b.Seek(offset, io.SeekStart)
+			if err != nil {
+				return nil, fmt.Errorf("can't rewind request body: %w", err)
+			}
+
+			return io.NopCloser(b), nil
+		}
+
+		return body, getBody, nil
+	default:
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("can't read request body: %w", err)
+		}
+
+		return bytes.NewReader(data), nil, nil
+	}
+}
+
+// makeBodyReplayable ensures the body of the given request can be obtained again
+// through its GetBody function, buffering it if necessary.
+func makeBodyReplayable(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return fmt.Errorf("can't read request body: %w", err)
+	}
+
+	_ = req.Body.Close()
+
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	return nil
+}
+
+// cloneRequest returns a copy of the given request with the given context,
+// whose body is a fresh reader to the original request body.
+func cloneRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
+	clone := req.Clone(ctx)
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("can't replay request body: %w", err)
+		}
+
+		clone.Body = body
+	}
+
+	return clone, nil
+}
+
 // paramsFromFields builds some [url.Values] from the given fields.
 func paramsFromFields(fields []string) url.Values {
 	if len(fields) == 0 || len(fields) == 1 && fields[0] == "" {
@@ -58,6 +133,17 @@ func paramsFromFields(fields []string) url.Values {
 	}
 }
 
+// resourceFromPath returns the resource targeted by the given request path,
+// e.g. "v1/agent/" for "/v1/agent/<id>/".
+func resourceFromPath(path string) Resource {
+	parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 3)
+	if len(parts) < 2 || parts[1] == "" {
+		return strings.TrimPrefix(path, "/")
+	}
+
+	return parts[0] + "/" + parts[1] + "/"
+}
+
 // cleanupResponse ensures we read the whole response to avoid "Connection reset by peer"
 // on server, and ensures that the HTTP connection can be reused.
 func cleanupResponse(resp *http.Response) {
@@ -111,10 +197,11 @@ func cloneMap[K comparable, V any](m map[K]V) map[K]V {
 }
 
 // readBodyStart reads the first errorRespMaxLength of the response body.
-func readBodyStart(body io.Reader) []byte {
+// Reading errors are reported to the given logger.
+func readBodyStart(body io.Reader, logger *slog.Logger) []byte {
 	content, err := io.ReadAll(io.LimitReader(body, errorRespMaxLength))
 	if err != nil {
-		log.Println("Error reading body:", err)
+		logger.Warn("Error reading response body", slog.Any("error", err))
 	}
 
 	return content